@@ -0,0 +1,226 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxActiveSpanNames bounds the span.name values of otelkit_active_spans;
+// spans with names beyond it are counted under activeSpanOverflowName.
+const maxActiveSpanNames = 200
+
+// activeSpanOverflowName labels live spans whose name did not fit in maxActiveSpanNames.
+const activeSpanOverflowName = "_other"
+
+// activeSpanProcessor is a SpanProcessor that counts live spans per name and,
+// when a leak threshold is set, reports spans left open past it or at Shutdown.
+//
+// Leak tracking costs one runtime.Callers call and a map entry per span; stacks
+// are only symbolized when a span is reported, so it is cheap enough for staging.
+//
+// Fields:
+//   - kit: Used to log leaked spans
+//   - leakThreshold: Age after which an open span is reported (0 disables tracking)
+//   - counts: Live spans per span name
+//   - live: Spans still open, with their creation stack (only with a leak threshold)
+//   - stop: Closed by Shutdown to stop the leak checker
+type activeSpanProcessor struct {
+	kit           *OTelKit
+	leakThreshold time.Duration
+
+	mu     sync.Mutex
+	counts map[string]int64
+	live   map[trace.SpanID]*liveSpan
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// liveSpan describes an open span tracked for leak detection.
+type liveSpan struct {
+	name        string
+	spanContext trace.SpanContext
+	start       time.Time
+	stack       []uintptr
+	reported    bool
+}
+
+// newActiveSpanProcessor creates the processor and, if leakThreshold is set,
+// starts a goroutine that checks for leaked spans every leakThreshold/2.
+func newActiveSpanProcessor(kit *OTelKit, leakThreshold time.Duration) *activeSpanProcessor {
+	p := &activeSpanProcessor{
+		kit:           kit,
+		leakThreshold: leakThreshold,
+		counts:        make(map[string]int64),
+		stop:          make(chan struct{}),
+	}
+	if leakThreshold > 0 {
+		p.live = make(map[trace.SpanID]*liveSpan)
+		p.wg.Add(1)
+		go p.checkLeaks(max(leakThreshold/2, 10*time.Millisecond))
+	}
+	return p
+}
+
+// countKey returns the span.name value a span is counted under. Callers hold p.mu.
+func (p *activeSpanProcessor) countKey(name string) string {
+	if _, ok := p.counts[name]; ok || len(p.counts) < maxActiveSpanNames {
+		return name
+	}
+	return activeSpanOverflowName
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *activeSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	var stack []uintptr
+	if p.live != nil {
+		stack = make([]uintptr, 32)
+		stack = stack[:runtime.Callers(2, stack)]
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.counts[p.countKey(s.Name())]++
+	if p.live != nil {
+		p.live[s.SpanContext().SpanID()] = &liveSpan{
+			name:        s.Name(),
+			spanContext: s.SpanContext(),
+			start:       s.StartTime(),
+			stack:       stack,
+		}
+	}
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *activeSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := p.countKey(s.Name())
+	if p.counts[key] > 0 {
+		p.counts[key]--
+	}
+	if p.live != nil {
+		delete(p.live, s.SpanContext().SpanID())
+	}
+}
+
+// Shutdown implements sdktrace.SpanProcessor. It stops the leak checker and
+// logs every span that is still open, since it can no longer be exported.
+func (p *activeSpanProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+		p.wg.Wait()
+
+		now := time.Now()
+		for _, span := range p.openSpans(0) {
+			p.reportLeak(span, now, "Span still open at shutdown")
+		}
+	})
+	return nil
+}
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (p *activeSpanProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// snapshot returns the live span count per span name.
+func (p *activeSpanProcessor) snapshot() map[string]int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	counts := make(map[string]int64, len(p.counts))
+	for name, count := range p.counts {
+		counts[name] = count
+	}
+	return counts
+}
+
+// checkLeaks periodically reports spans open longer than the leak threshold.
+func (p *activeSpanProcessor) checkLeaks(interval time.Duration) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case now := <-ticker.C:
+			for _, span := range p.newLeaks(now) {
+				p.reportLeak(span, now, "Span still open past leak threshold")
+			}
+		}
+	}
+}
+
+// newLeaks marks and returns the spans that crossed the leak threshold since the last check.
+func (p *activeSpanProcessor) newLeaks(now time.Time) []liveSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var leaks []liveSpan
+	for _, span := range p.live {
+		if !span.reported && now.Sub(span.start) >= p.leakThreshold {
+			span.reported = true
+			leaks = append(leaks, *span)
+		}
+	}
+	return leaks
+}
+
+// openSpans returns the tracked spans open for at least minAge, oldest first.
+func (p *activeSpanProcessor) openSpans(minAge time.Duration) []liveSpan {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var spans []liveSpan
+	for _, span := range p.live {
+		if now.Sub(span.start) >= minAge {
+			spans = append(spans, *span)
+		}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start.Before(spans[j].start) })
+	return spans
+}
+
+// reportLeak logs a leaked span with its name, age and creation stack.
+func (p *activeSpanProcessor) reportLeak(span liveSpan, now time.Time, msg string) {
+	ctx := trace.ContextWithSpanContext(context.Background(), span.spanContext)
+	p.kit.LogWarn(ctx, msg,
+		slog.String("span_name", span.name),
+		slog.Duration("age", now.Sub(span.start)),
+		slog.Duration("threshold", p.leakThreshold),
+		slog.String("stacktrace", formatStack(span.stack)),
+	)
+}
+
+// formatStack renders program counters like debug.Stack, skipping the
+// OpenTelemetry SDK frames between the caller and the processor.
+func formatStack(pcs []uintptr) string {
+	var b strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "go.opentelemetry.io/otel/") {
+			fmt.Fprintf(&b, "%s\n\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
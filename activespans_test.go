@@ -0,0 +1,285 @@
+package otelkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent log writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestActiveSpanProcessor(t *testing.T) {
+	kit, _ := newRecordingKit(t, Config{ServiceName: "active-test"})
+	ctx := context.Background()
+
+	t.Run("CountsAllHelpers", func(t *testing.T) {
+		_, outer := kit.StartSpan(ctx, "outer")
+		kit.TraceFunction(ctx, "inner", func(ctx context.Context) error {
+			counts := kit.activeSpans.snapshot()
+			if counts["outer"] != 1 || counts["inner"] != 1 {
+				t.Errorf("Expected outer and inner to be live, got %v", counts)
+			}
+			return nil
+		})
+		outer.End()
+
+		counts := kit.activeSpans.snapshot()
+		if counts["outer"] != 0 || counts["inner"] != 0 {
+			t.Errorf("Expected no live spans, got %v", counts)
+		}
+	})
+
+	t.Run("NeverNegative", func(t *testing.T) {
+		_, span := kit.StartSpan(ctx, "twice")
+		span.End()
+		span.End()
+		if n := kit.activeSpans.snapshot()["twice"]; n != 0 {
+			t.Errorf("Expected 0, got %d", n)
+		}
+	})
+
+	t.Run("NameOverflow", func(t *testing.T) {
+		for i := 0; i < maxActiveSpanNames+5; i++ {
+			_, span := kit.StartSpan(ctx, fmt.Sprintf("span-%d", i))
+			defer span.End()
+		}
+		counts := kit.activeSpans.snapshot()
+		if len(counts) > maxActiveSpanNames+1 {
+			t.Errorf("Expected at most %d names, got %d", maxActiveSpanNames+1, len(counts))
+		}
+		if counts[activeSpanOverflowName] == 0 {
+			t.Errorf("Expected spans counted under %s", activeSpanOverflowName)
+		}
+	})
+}
+
+func TestSpanLeakDetection(t *testing.T) {
+	kit, _ := newRecordingKit(t, Config{ServiceName: "leak-test"})
+	logs := &syncBuffer{}
+	kit.logger = slog.New(slog.NewJSONHandler(logs, nil))
+
+	processor := newActiveSpanProcessor(kit, 20*time.Millisecond)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+	defer provider.Shutdown(context.Background())
+	tracer := provider.Tracer("leak-test")
+
+	_, closed := tracer.Start(context.Background(), "closed")
+	closed.End()
+	_, leaked := tracer.Start(context.Background(), "forgotten")
+	defer leaked.End()
+
+	waitFor(t, "leak warning", func() bool {
+		return strings.Contains(logs.String(), "Span still open past leak threshold")
+	})
+	time.Sleep(50 * time.Millisecond)
+
+	output := logs.String()
+	if strings.Count(output, "past leak threshold") != 1 {
+		t.Errorf("Expected exactly one warning, got:\n%s", output)
+	}
+	if !strings.Contains(output, `"span_name":"forgotten"`) {
+		t.Errorf("Warning does not name the leaked span:\n%s", output)
+	}
+	if !strings.Contains(output, "TestSpanLeakDetection") || strings.Contains(output, "go.opentelemetry.io/otel/sdk") {
+		t.Errorf("Stack trace should point at the caller, not the SDK:\n%s", output)
+	}
+}
+
+func TestSpanDebugHandler(t *testing.T) {
+	kit, _ := newRecordingKit(t, Config{ServiceName: "leak-test"})
+	logs := &syncBuffer{}
+	kit.logger = slog.New(slog.NewJSONHandler(logs, nil))
+
+	kit.activeSpans = newActiveSpanProcessor(kit, time.Hour)
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(kit.activeSpans))
+	kit.tracer = provider.Tracer("leak-test")
+
+	_, old := kit.StartSpan(context.Background(), "old")
+	time.Sleep(20 * time.Millisecond)
+	_, recent := kit.StartSpan(context.Background(), "recent")
+	_, done := kit.StartSpan(context.Background(), "done")
+	done.End()
+
+	t.Run("ListsOpenSpans", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		kit.SpanDebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/spans", nil))
+
+		var report spanDebugReport
+		if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+			t.Fatalf("Invalid JSON: %v\n%s", err, rec.Body.String())
+		}
+		if !report.LeakDetection || report.ActiveSpans["old"] != 1 || report.ActiveSpans["done"] != 0 {
+			t.Errorf("Unexpected report: %+v", report)
+		}
+		if len(report.OpenSpans) != 2 || report.OpenSpans[0].Name != "old" || report.OpenSpans[1].Name != "recent" {
+			t.Fatalf("Expected old and recent spans, oldest first, got %+v", report.OpenSpans)
+		}
+		if report.OpenSpans[0].SpanID != old.SpanContext().SpanID().String() || !strings.Contains(report.OpenSpans[0].Stack, "TestSpanDebugHandler") {
+			t.Errorf("Unexpected span details: %+v", report.OpenSpans[0])
+		}
+	})
+
+	t.Run("MinAge", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		kit.SpanDebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/spans?min_age=15ms", nil))
+
+		var report spanDebugReport
+		json.Unmarshal(rec.Body.Bytes(), &report)
+		if len(report.OpenSpans) != 1 || report.OpenSpans[0].Name != "old" {
+			t.Errorf("Expected only the old span, got %+v", report.OpenSpans)
+		}
+
+		rec = httptest.NewRecorder()
+		kit.SpanDebugHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/spans?min_age=soon", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for invalid min_age, got %d", rec.Code)
+		}
+	})
+
+	t.Run("ReportedAtShutdown", func(t *testing.T) {
+		recent.End()
+		provider.Shutdown(context.Background())
+
+		output := logs.String()
+		if strings.Count(output, "Span still open at shutdown") != 1 || !strings.Contains(output, `"span_name":"old"`) {
+			t.Errorf("Expected a shutdown warning for the old span only, got:\n%s", output)
+		}
+	})
+}
+
+// TestActiveSpansUnsampled goes through New so the real sampler is in place:
+// spans dropped by SampleRate or the none exporter must still be counted.
+func TestActiveSpansUnsampled(t *testing.T) {
+	collector := newOTLPStub(t)
+
+	for _, tc := range []struct {
+		name     string
+		exporter ExporterType
+	}{
+		{"DefaultSampleRate", ExporterOTLP},
+		{"ExporterNone", ExporterNone},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ServiceName = "active-unsampled"
+			config.ExporterType = tc.exporter
+			config.OTLPEndpoint = collector.endpoint()
+			config.EnableMetrics = false
+			config.EnableLogs = false
+			config.SpanLeakThreshold = time.Hour
+			kit, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to initialize OTelKit: %v", err)
+			}
+			defer kit.Shutdown(context.Background())
+
+			const n = 50
+			spans := make([]trace.Span, 0, n)
+			for i := 0; i < n; i++ {
+				_, span := kit.StartSpan(context.Background(), "unsampled")
+				spans = append(spans, span)
+			}
+			if got := kit.activeSpans.snapshot()["unsampled"]; got != n {
+				t.Errorf("Expected %d live spans, got %d", n, got)
+			}
+			if got := len(kit.activeSpans.openSpans(0)); got != n {
+				t.Errorf("Expected %d spans tracked for leaks, got %d", n, got)
+			}
+			for _, span := range spans {
+				span.End()
+			}
+			if got := kit.activeSpans.snapshot()["unsampled"]; got != 0 {
+				t.Errorf("Expected no live spans, got %d", got)
+			}
+		})
+	}
+}
+
+// TestUnsampledSpansNotRecorded checks that without leak detection or span
+// metrics, dropped spans stay non-recording and skip the span processors.
+func TestUnsampledSpansNotRecorded(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		modify func(*Config)
+	}{
+		{"SampleRate", func(c *Config) {
+			c.ExporterType = ExporterOTLP
+			c.OTLPEndpoint = newOTLPStub(t).endpoint()
+			c.SampleRate = 0
+		}},
+		{"ExporterNone", func(c *Config) { c.ExporterType = ExporterNone }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.ServiceName = "active-not-recorded"
+			config.EnableMetrics = false
+			config.EnableLogs = false
+			tc.modify(&config)
+			kit, err := New(config)
+			if err != nil {
+				t.Fatalf("Failed to initialize OTelKit: %v", err)
+			}
+			defer kit.Shutdown(context.Background())
+
+			_, span := kit.StartSpan(context.Background(), "unsampled")
+			defer span.End()
+			if span.IsRecording() {
+				t.Error("Expected an unsampled span to be non-recording")
+			}
+			if got := kit.activeSpans.snapshot()["unsampled"]; got != 0 {
+				t.Errorf("Expected the span not to be counted, got %d", got)
+			}
+		})
+	}
+}
+
+func BenchmarkActiveSpanProcessor(b *testing.B) {
+	for _, tc := range []struct {
+		name      string
+		threshold time.Duration
+	}{
+		{"Counting", 0},
+		{"LeakDetection", time.Hour},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			processor := newActiveSpanProcessor(&OTelKit{}, tc.threshold)
+			provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+			defer provider.Shutdown(context.Background())
+			tracer := provider.Tracer("bench")
+			ctx := context.Background()
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, span := tracer.Start(ctx, "op")
+				span.End()
+			}
+		})
+	}
+}
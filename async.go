@@ -0,0 +1,115 @@
+package otelkit
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+// Go runs fn in a new goroutine under its own span, for fire-and-forget work
+// started from a request.
+//
+// Parameters:
+//   - ctx: Context carrying the trace (its cancellation and deadline are not inherited)
+//   - name: Descriptive name for the span
+//   - fn: The background work
+//   - opts: Optional span settings (e.g., WithLinkedParent, WithAttributes)
+//
+// The goroutine's context keeps the trace context and values of ctx but is
+// detached from its cancellation (context.WithoutCancel), so the work is not
+// aborted when the request that started it completes. The span is a child of
+// the span in ctx; pass WithLinkedParent to start a new trace linked to it
+// instead, which keeps the request trace's duration accurate.
+//
+// Panics in fn are recovered and recorded on the span (a goroutine panic would
+// otherwise crash the process). Errors and panics are logged with trace
+// correlation.
+//
+// Example:
+//   kit.Go(r.Context(), "send_welcome_email", func(ctx context.Context) error {
+//       return mailer.SendWelcome(ctx, user)
+//   })
+func (o *OTelKit) Go(ctx context.Context, name string, fn func(ctx context.Context) error, opts ...TraceOption) {
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		err := o.traceWithOptions(ctx, name, fn, slices.Concat(opts, []TraceOption{withPanicRecovery()})...)
+		if err != nil {
+			o.LogError(ctx, "Background task failed", err, slog.String("task", name))
+		}
+	}()
+}
+
+// Group runs a set of traced goroutines and waits for them, like errgroup.Group.
+// Create one with OTelKit.Group.
+type Group struct {
+	kit    *OTelKit
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// Group returns a Group whose goroutines run as child spans of the span in ctx,
+// and a derived context that is canceled when a goroutine first fails or when
+// Wait returns.
+//
+// Parameters:
+//   - ctx: Parent context (may contain parent span)
+//
+// Returns:
+//   - *Group: The group to start goroutines with
+//   - context.Context: The group context; pass it to work that should stop when the group fails
+//
+// Unlike Go, a Group keeps the cancellation of ctx: Wait joins the goroutines,
+// so they never outlive the caller.
+//
+// Example:
+//   g, gctx := kit.Group(ctx)
+//   g.Go("load_user", func(ctx context.Context) error { return loadUser(ctx, id) })
+//   g.Go("load_orders", func(ctx context.Context) error { return loadOrders(ctx, id) })
+//   if err := g.Wait(); err != nil {
+//       return err
+//   }
+func (o *OTelKit) Group(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &Group{kit: o, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Go runs fn in a new goroutine under a span named name.
+//
+// Parameters:
+//   - name: Descriptive name for the span
+//   - fn: The work; receives the group context enriched with its span
+//   - opts: Optional span settings
+//
+// The first non-nil error (including a recovered panic, as *PanicError)
+// cancels the group context and is returned by Wait.
+func (g *Group) Go(name string, fn func(ctx context.Context) error, opts ...TraceOption) {
+	g.wg.Add(1)
+
+	go func() {
+		defer g.wg.Done()
+
+		err := g.kit.traceWithOptions(g.ctx, name, fn, slices.Concat(opts, []TraceOption{withPanicRecovery()})...)
+		if err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel(err)
+			})
+		}
+	}()
+}
+
+// Wait blocks until every goroutine started with Go has returned.
+//
+// Returns:
+//   - error: The first error returned by a goroutine, or nil
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel(nil)
+	return g.err
+}
@@ -0,0 +1,185 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// activeSpans returns the current value of otelkit_active_spans.
+func activeSpans(t *testing.T, reader *sdkmetric.ManualReader) int64 {
+	t.Helper()
+	for _, m := range collectMetrics(t, reader) {
+		if m.Name == "otelkit_active_spans" {
+			var total int64
+			for _, dp := range m.Data.(metricdata.Gauge[int64]).DataPoints {
+				total += dp.Value
+			}
+			return total
+		}
+	}
+	return 0
+}
+
+func TestGo(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "async-test"})
+	reader := sdkmetric.NewManualReader()
+	if err := kit.initMetricsInstruments(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("otelkit-test")); err != nil {
+		t.Fatalf("initMetricsInstruments failed: %v", err)
+	}
+
+	t.Run("OutlivesRequest", func(t *testing.T) {
+		recorder.Reset()
+		release := make(chan struct{})
+		done := make(chan error, 1)
+
+		reqCtx, cancel := context.WithCancel(context.Background())
+		reqCtx, reqSpan := kit.StartSpan(reqCtx, "request")
+		kit.Go(reqCtx, "background", func(ctx context.Context) error {
+			<-release
+			done <- ctx.Err()
+			return nil
+		})
+
+		// The request finishes before the background task
+		reqSpan.End()
+		cancel()
+		waitFor(t, "1 active span while the task runs", func() bool { return activeSpans(t, reader) == 1 })
+		close(release)
+
+		if err := <-done; err != nil {
+			t.Errorf("Background context was canceled with the request: %v", err)
+		}
+		waitFor(t, "background span", spanEnded(recorder, "background"))
+
+		span := findSpan(t, recorder.Ended(), "background")
+		if span.Parent().SpanID() != reqSpan.SpanContext().SpanID() {
+			t.Error("Expected background span to be a child of the request span")
+		}
+		waitFor(t, "active spans to drop to 0", func() bool { return activeSpans(t, reader) == 0 })
+	})
+
+	t.Run("LinkedParentAndPanic", func(t *testing.T) {
+		recorder.Reset()
+		ctx, reqSpan := kit.StartSpan(context.Background(), "request")
+		kit.Go(ctx, "crashy", func(ctx context.Context) error {
+			panic("background boom")
+		}, WithLinkedParent())
+		reqSpan.End()
+
+		waitFor(t, "crashy span", spanEnded(recorder, "crashy"))
+		span := findSpan(t, recorder.Ended(), "crashy")
+		if span.Parent().IsValid() || span.SpanContext().TraceID() == reqSpan.SpanContext().TraceID() {
+			t.Error("Expected a new trace")
+		}
+		if links := span.Links(); len(links) != 1 || links[0].SpanContext.SpanID() != reqSpan.SpanContext().SpanID() {
+			t.Errorf("Expected a link to the request span, got %v", links)
+		}
+		assertRecoveredPanic(t, span, "background boom")
+	})
+
+	t.Run("SharedOptions", func(t *testing.T) {
+		recorder.Reset()
+		// Spare capacity must not be written to by concurrent calls
+		opts := make([]TraceOption, 1, 4)
+		opts[0] = WithAttributes(attribute.String("task", "shared"))
+
+		const n = 8
+		for i := 0; i < n; i++ {
+			kit.Go(context.Background(), "shared", func(ctx context.Context) error { return nil }, opts...)
+		}
+		waitFor(t, "shared spans", func() bool { return len(recorder.Ended()) == n })
+		if spare := opts[:cap(opts)][1]; spare != nil {
+			t.Error("Expected the caller's options slice to be left untouched")
+		}
+	})
+}
+
+func TestGroup(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "async-test"})
+	ctx, parent := kit.StartSpan(context.Background(), "parent")
+	defer parent.End()
+
+	failure := errors.New("load failed")
+	g, gctx := kit.Group(ctx)
+	g.Go("fails", func(ctx context.Context) error {
+		return failure
+	})
+	g.Go("waits", func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(5 * time.Second):
+			return errors.New("group context not canceled")
+		}
+	})
+	g.Go("panics", func(ctx context.Context) error {
+		<-ctx.Done()
+		panic("group boom")
+	})
+
+	if err := g.Wait(); !errors.Is(err, failure) {
+		t.Errorf("Expected first error from Wait, got %v", err)
+	}
+	if gctx.Err() == nil {
+		t.Error("Expected group context to be canceled after Wait")
+	}
+
+	for _, name := range []string{"fails", "waits", "panics"} {
+		span := findSpan(t, recorder.Ended(), name)
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Errorf("Span %s is not a child of the parent span", name)
+		}
+	}
+	assertRecoveredPanic(t, findSpan(t, recorder.Ended(), "panics"), "group boom")
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// spanEnded reports whether the recorder has an ended span with the given name.
+func spanEnded(recorder *tracetest.SpanRecorder, name string) func() bool {
+	return func() bool {
+		for _, span := range recorder.Ended() {
+			if span.Name() == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// assertRecoveredPanic checks that span recorded a panic that did not escape.
+func assertRecoveredPanic(t *testing.T, span sdktrace.ReadOnlySpan, message string) {
+	t.Helper()
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", span.Status().Code)
+	}
+	for _, event := range span.Events() {
+		if event.Name == "exception" {
+			attrs := attributeMap(event.Attributes)
+			if attrs["exception.message"] != message || attrs["exception.escaped"] != "false" {
+				t.Errorf("Unexpected exception event: %v", attrs)
+			}
+			return
+		}
+	}
+	t.Errorf("No exception event on span %s", span.Name())
+}
@@ -0,0 +1,260 @@
+package otelkit
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// BatchOption configures ProcessBatch.
+type BatchOption func(*batchConfig)
+
+// batchConfig collects the settings applied by BatchOption values.
+//
+// Fields:
+//   - concurrency: Maximum number of items processed at once
+//   - itemSpans: Create a child span per item
+//   - traceOpts: Span settings for the batch span
+type batchConfig struct {
+	concurrency int
+	itemSpans   bool
+	traceOpts   []TraceOption
+}
+
+// WithConcurrency processes up to n items at once (default 1, sequential).
+func WithConcurrency(n int) BatchOption {
+	return func(c *batchConfig) {
+		c.concurrency = max(n, 1)
+	}
+}
+
+// WithItemSpans creates a child span "batch.{name}.item" for every item.
+// Leave it off for large batches of cheap items.
+func WithItemSpans() BatchOption {
+	return func(c *batchConfig) {
+		c.itemSpans = true
+	}
+}
+
+// WithBatchTraceOptions passes span settings (WithLinks, WithAttributes, ...)
+// through to the batch span.
+func WithBatchTraceOptions(opts ...TraceOption) BatchOption {
+	return func(c *batchConfig) {
+		c.traceOpts = append(c.traceOpts, opts...)
+	}
+}
+
+// ProcessBatch traces a batch and calls fn once per item, reporting partial
+// failures instead of treating the batch as a single success or failure.
+//
+// Parameters:
+//   - kit: The OTelKit instance that creates the spans and metrics
+//   - ctx: Context for the operation (may contain parent span)
+//   - name: Name of the batch operation (e.g., "send_emails")
+//   - items: The items to process
+//   - fn: Processes one item; receives the item span context (or the batch span
+//     context without WithItemSpans)
+//   - opts: Optional settings (WithConcurrency, WithItemSpans, WithBatchTraceOptions)
+//
+// Returns:
+//   - error: The failures of all items joined with errors.Join in item order, each
+//     prefixed with the item index, plus ctx.Err() if the batch was cut short
+//
+// Every item runs even after another fails. Once ctx is done no new items are
+// started; items already running finish and the rest are counted as skipped.
+// A panic in fn stops the batch instead: items already running finish and the
+// panic is re-raised on the calling goroutine, where the batch span records it
+// and Config.RecoverPanics decides whether it is returned as a *PanicError.
+// With a concurrency above 1 the re-raised value is an error whose message holds
+// the item's stack trace; errors.As extracts the original *PanicError from it.
+//
+// The span "batch.{name}" carries batch.operation, batch.item_count,
+// batch.concurrency, batch.processed_count, batch.failed_count and batch.skipped_count.
+//
+// Metrics, labelled with batch.operation:
+//   - batch_items_processed_total: Items fn ran for
+//   - batch_items_failed_total: Items fn returned an error for
+//   - batch_duration_seconds: Histogram of whole-batch duration
+//
+// Example:
+//   err := otelkit.ProcessBatch(kit, ctx, "send_emails", emails, func(ctx context.Context, e Email) error {
+//       return mailer.Send(ctx, e)
+//   }, otelkit.WithConcurrency(8), otelkit.WithItemSpans())
+func ProcessBatch[T any](kit *OTelKit, ctx context.Context, name string, items []T, fn func(ctx context.Context, item T) error, opts ...BatchOption) error {
+	cfg := &batchConfig{concurrency: 1}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+
+	start := time.Now()
+	err := kit.traceWithOptions(ctx, "batch."+name, func(ctx context.Context) error {
+		errs := make([]error, len(items))
+		run := func(i int) {
+			errs[i] = kit.processBatchItem(ctx, name, i, func(ctx context.Context) error {
+				return fn(ctx, items[i])
+			}, cfg.itemSpans)
+		}
+
+		var started int
+		if cfg.concurrency == 1 {
+			// Inline, so a panic unwinds the caller's goroutine as usual
+			for i := range items {
+				if ctx.Err() != nil {
+					break
+				}
+				started++
+				run(i)
+			}
+		} else {
+			started = runConcurrently(ctx, len(items), cfg.concurrency, run)
+		}
+
+		failed := 0
+		var joined []error
+		for _, err := range errs {
+			if err != nil {
+				failed++
+				joined = append(joined, err)
+			}
+		}
+		skipped := len(items) - started
+		if skipped > 0 {
+			joined = append(joined, fmt.Errorf("batch %s: %d items skipped: %w", name, skipped, ctx.Err()))
+		}
+
+		trace.SpanFromContext(ctx).SetAttributes(
+			attribute.Int("batch.processed_count", started),
+			attribute.Int("batch.failed_count", failed),
+			attribute.Int("batch.skipped_count", skipped),
+		)
+		kit.recordBatchItems(ctx, name, started, failed)
+		return errors.Join(joined...)
+	}, append([]TraceOption{WithAttributes(
+		attribute.String("batch.operation", name),
+		attribute.Int("batch.item_count", len(items)),
+		attribute.Int("batch.concurrency", cfg.concurrency),
+	)}, cfg.traceOpts...)...)
+
+	if kit.batchDuration != nil {
+		kit.batchDuration.Record(ctx, time.Since(start).Seconds(),
+			metric.WithAttributes(attribute.String("batch.operation", name)))
+	}
+	return err
+}
+
+// itemPanic carries a panic from a batch worker to the caller's goroutine. Its
+// message includes the worker's stack, so a crash report shows where the item
+// panicked rather than where the panic was re-raised.
+type itemPanic struct {
+	err *PanicError
+}
+
+// Error implements the error interface.
+func (p *itemPanic) Error() string {
+	return fmt.Sprintf("%v\n\n%s", p.err.Value, p.err.Stack)
+}
+
+// Unwrap returns the recovered panic.
+func (p *itemPanic) Unwrap() error {
+	return p.err
+}
+
+// runConcurrently calls run for items 0..n-1, at most concurrency at once, and
+// returns how many it started. No item is started once ctx is done. A panic in
+// run stops new items from starting and is re-raised on the caller's goroutine,
+// as an *itemPanic, after the running items finish, where the batch span records it.
+func runConcurrently(ctx context.Context, n, concurrency int, run func(i int)) int {
+	sem := make(chan struct{}, concurrency)
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	var panicOnce sync.Once
+	var panicked *itemPanic
+
+	started := 0
+schedule:
+	for i := 0; i < n; i++ {
+		select {
+		case <-ctx.Done():
+			break schedule
+		case <-stop:
+			break schedule
+		case sem <- struct{}{}:
+		}
+		// Several cases may be ready; do not start new work once ctx is done or an item panicked
+		select {
+		case <-stop:
+			<-sem
+			break schedule
+		default:
+		}
+		if ctx.Err() != nil {
+			<-sem
+			break schedule
+		}
+
+		started++
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer func() {
+				if rec := recover(); rec != nil {
+					// Drop the "goroutine N [running]:" line, which names the worker
+					stack := debug.Stack()
+					if line := bytes.IndexByte(stack, '\n'); line >= 0 {
+						stack = stack[line+1:]
+					}
+					panicOnce.Do(func() {
+						panicked = &itemPanic{&PanicError{Value: rec, Stack: stack}}
+						close(stop)
+					})
+				}
+			}()
+			run(i)
+		}(i)
+	}
+	wg.Wait()
+
+	if panicked != nil {
+		panic(panicked)
+	}
+	return started
+}
+
+// processBatchItem runs one item, under its own span when itemSpans is set,
+// and prefixes a failure with the item index.
+func (o *OTelKit) processBatchItem(ctx context.Context, name string, index int, fn func(ctx context.Context) error, itemSpans bool) error {
+	var err error
+	if itemSpans {
+		err = o.traceWithOptions(ctx, "batch."+name+".item", fn,
+			WithAttributes(attribute.Int("batch.item.index", index)),
+		)
+	} else {
+		err = fn(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("batch item %d: %w", index, err)
+	}
+	return nil
+}
+
+// recordBatchItems adds the processed and failed item counts of one batch.
+func (o *OTelKit) recordBatchItems(ctx context.Context, name string, processed, failed int) {
+	attrs := metric.WithAttributes(attribute.String("batch.operation", name))
+	if o.batchItemsProcessed != nil {
+		o.batchItemsProcessed.Add(ctx, int64(processed), attrs)
+	}
+	if o.batchItemsFailed != nil {
+		o.batchItemsFailed.Add(ctx, int64(failed), attrs)
+	}
+}
@@ -0,0 +1,177 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestProcessBatch(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "batch-test"})
+	reader := sdkmetric.NewManualReader()
+	if err := kit.initMetricsInstruments(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("otelkit-test")); err != nil {
+		t.Fatalf("initMetricsInstruments failed: %v", err)
+	}
+
+	t.Run("PartialFailure", func(t *testing.T) {
+		recorder.Reset()
+		var inFlight, maxInFlight atomic.Int32
+
+		err := ProcessBatch(kit, context.Background(), "square", []int{1, 2, 3, 4, 5, 6}, func(ctx context.Context, n int) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			if n%3 == 0 {
+				return fmt.Errorf("%d is divisible by 3", n)
+			}
+			return nil
+		}, WithConcurrency(2), WithItemSpans())
+
+		if got := maxInFlight.Load(); got > 2 {
+			t.Errorf("Expected at most 2 concurrent items, got %d", got)
+		}
+		expected := "batch item 2: 3 is divisible by 3\nbatch item 5: 6 is divisible by 3"
+		if err == nil || err.Error() != expected {
+			t.Errorf("Unexpected error:\n expected %q\n got      %v", expected, err)
+		}
+
+		span := findSpan(t, recorder.Ended(), "batch.square")
+		attrs := attributeMap(span.Attributes())
+		if attrs["batch.processed_count"] != "6" || attrs["batch.failed_count"] != "2" || attrs["batch.skipped_count"] != "0" {
+			t.Errorf("Unexpected batch attributes: %v", attrs)
+		}
+
+		itemSpans := 0
+		for _, s := range recorder.Ended() {
+			if s.Name() == "batch.square.item" {
+				itemSpans++
+				if s.Parent().SpanID() != span.SpanContext().SpanID() {
+					t.Error("Item span is not a child of the batch span")
+				}
+			}
+		}
+		if itemSpans != 6 {
+			t.Errorf("Expected 6 item spans, got %d", itemSpans)
+		}
+	})
+
+	t.Run("Cancellation", func(t *testing.T) {
+		recorder.Reset()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var calls atomic.Int32
+		err := ProcessBatch(kit, ctx, "drain", make([]struct{}, 10), func(ctx context.Context, _ struct{}) error {
+			if calls.Add(1) == 3 {
+				cancel()
+			}
+			return nil
+		})
+
+		if calls.Load() != 3 {
+			t.Errorf("Expected scheduling to stop after 3 items, got %d", calls.Load())
+		}
+		if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "7 items skipped") {
+			t.Errorf("Unexpected error: %v", err)
+		}
+		attrs := attributeMap(findSpan(t, recorder.Ended(), "batch.drain").Attributes())
+		if attrs["batch.processed_count"] != "3" || attrs["batch.skipped_count"] != "7" {
+			t.Errorf("Unexpected batch attributes: %v", attrs)
+		}
+	})
+
+	t.Run("Panic", func(t *testing.T) {
+		for _, concurrency := range []int{1, 4} {
+			recorder.Reset()
+			var calls atomic.Int32
+
+			rec := func() (rec any) {
+				defer func() { rec = recover() }()
+				ProcessBatch(kit, context.Background(), "explode", make([]struct{}, 20), func(ctx context.Context, _ struct{}) error {
+					explodeBatchItem(calls.Add(1) == 2)
+					time.Sleep(time.Millisecond)
+					return nil
+				}, WithConcurrency(concurrency))
+				return nil
+			}()
+
+			if concurrency == 1 {
+				if rec != "boom" {
+					t.Errorf("Expected the panic to unwind the calling goroutine, got %v", rec)
+				}
+			} else {
+				// Re-raised on the calling goroutine with the item's stack
+				err, _ := rec.(error)
+				var panicErr *PanicError
+				if !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+					t.Fatalf("Concurrency %d: expected the panic on the calling goroutine, got %v", concurrency, rec)
+				}
+				if !strings.Contains(err.Error(), "explodeBatchItem") {
+					t.Errorf("Concurrency %d: expected the item's stack in the panic, got %q", concurrency, err.Error())
+				}
+			}
+			if n := calls.Load(); n >= 20 {
+				t.Errorf("Concurrency %d: expected the batch to stop after the panic, got %d calls", concurrency, n)
+			}
+			span := findSpan(t, recorder.Ended(), "batch.explode")
+			if len(span.Events()) == 0 || span.Events()[0].Name != "exception" {
+				t.Errorf("Concurrency %d: expected the batch span to record the panic", concurrency)
+			}
+		}
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		totals := map[string]int64{}
+		batches := map[string]uint64{}
+		for _, m := range collectMetrics(t, reader) {
+			switch m.Name {
+			case "batch_items_processed_total", "batch_items_failed_total":
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					op, _ := dp.Attributes.Value("batch.operation")
+					totals[m.Name+"/"+op.AsString()] += dp.Value
+				}
+			case "batch_duration_seconds":
+				for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+					op, _ := dp.Attributes.Value("batch.operation")
+					batches[op.AsString()] += dp.Count
+				}
+			}
+		}
+
+		expected := map[string]int64{
+			"batch_items_processed_total/square": 6,
+			"batch_items_failed_total/square":    2,
+			"batch_items_processed_total/drain":  3,
+			"batch_items_failed_total/drain":     0,
+		}
+		for key, want := range expected {
+			if totals[key] != want {
+				t.Errorf("%s: expected %d, got %d", key, want, totals[key])
+			}
+		}
+		if batches["square"] != 1 || batches["drain"] != 1 {
+			t.Errorf("Expected one duration measurement per batch, got %v", batches)
+		}
+	})
+}
+
+// explodeBatchItem panics when explode is set; its frame must show up in the
+// stack of a re-raised batch panic.
+func explodeBatchItem(explode bool) {
+	if explode {
+		panic("boom")
+	}
+}
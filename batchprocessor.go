@@ -0,0 +1,163 @@
+package otelkit
+
+import (
+	"fmt"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Batch processor defaults from the OpenTelemetry specification, used by
+// DefaultConfig when the OTEL_BSP_* and OTEL_BLRP_* variables are unset.
+const (
+	defaultSpanScheduleDelay  = 5 * time.Second
+	defaultLogScheduleDelay   = time.Second
+	defaultBatchExportTimeout = 30 * time.Second
+	defaultBatchMaxQueueSize  = 2048
+	defaultBatchMaxExportSize = 512
+)
+
+// BatchProcessorConfig tunes the span processor (Config.SpanProcessor) or log
+// processor (Config.LogProcessor) of every exporter of the signal, or of one
+// exporter (ExporterConfig.Processor). Zero values use the SDK defaults.
+//
+// Fields:
+//   - MaxQueueSize: Spans or records buffered before new ones are dropped
+//   - MaxExportBatchSize: Spans or records sent in one export; capped at MaxQueueSize
+//   - ScheduleDelay: Longest wait before a partial batch is exported
+//   - ExportTimeout: Bound on each export
+//   - Synchronous: Export each span or record as it ends instead of batching,
+//     for CLI tools and tests; blocks the caller and ignores the other fields
+//
+// Example:
+//   config.SpanProcessor = otelkit.BatchProcessorConfig{
+//       MaxQueueSize:  8192,
+//       ScheduleDelay: time.Second,
+//   }
+type BatchProcessorConfig struct {
+	MaxQueueSize       int
+	MaxExportBatchSize int
+	ScheduleDelay      time.Duration
+	ExportTimeout      time.Duration
+	Synchronous        bool
+}
+
+// batchProcessorConfigFromEnv reads a BatchProcessorConfig from the variables
+// with the given prefix (OTEL_BSP or OTEL_BLRP); durations are in milliseconds.
+func batchProcessorConfigFromEnv(prefix string, scheduleDelay time.Duration) BatchProcessorConfig {
+	return BatchProcessorConfig{
+		MaxQueueSize:       getEnvIntOrDefault(prefix+"_MAX_QUEUE_SIZE", defaultBatchMaxQueueSize),
+		MaxExportBatchSize: getEnvIntOrDefault(prefix+"_MAX_EXPORT_BATCH_SIZE", defaultBatchMaxExportSize),
+		ScheduleDelay:      getEnvMillisOrDefault(prefix+"_SCHEDULE_DELAY", scheduleDelay),
+		ExportTimeout:      getEnvMillisOrDefault(prefix+"_EXPORT_TIMEOUT", defaultBatchExportTimeout),
+	}
+}
+
+// validate rejects negative sizes and durations.
+func (b BatchProcessorConfig) validate() error {
+	if b.MaxQueueSize < 0 || b.MaxExportBatchSize < 0 {
+		return fmt.Errorf("batch processor sizes must not be negative: queue %d, batch %d", b.MaxQueueSize, b.MaxExportBatchSize)
+	}
+	if b.ScheduleDelay < 0 || b.ExportTimeout < 0 {
+		return fmt.Errorf("batch processor durations must not be negative: delay %v, timeout %v", b.ScheduleDelay, b.ExportTimeout)
+	}
+	return nil
+}
+
+// overriddenBy returns b with the non-zero fields of override, as set for a
+// single exporter.
+func (b BatchProcessorConfig) overriddenBy(override BatchProcessorConfig) BatchProcessorConfig {
+	if override.MaxQueueSize > 0 {
+		b.MaxQueueSize = override.MaxQueueSize
+	}
+	if override.MaxExportBatchSize > 0 {
+		b.MaxExportBatchSize = override.MaxExportBatchSize
+	}
+	if override.ScheduleDelay > 0 {
+		b.ScheduleDelay = override.ScheduleDelay
+	}
+	if override.ExportTimeout > 0 {
+		b.ExportTimeout = override.ExportTimeout
+	}
+	b.Synchronous = b.Synchronous || override.Synchronous
+	return b
+}
+
+// capBatchSize returns MaxExportBatchSize, capped at MaxQueueSize when both are set.
+func (b BatchProcessorConfig) capBatchSize() int {
+	if b.MaxQueueSize > 0 {
+		return min(b.MaxExportBatchSize, b.MaxQueueSize)
+	}
+	return b.MaxExportBatchSize
+}
+
+// newSpanProcessor creates the span processor for exporter: a simple processor
+// when synchronous, otherwise a batch processor tuned by batch. With the stats
+// of an instrumented exporter, a countingSpanProcessor counts its queue.
+func newSpanProcessor(exporter sdktrace.SpanExporter, batch BatchProcessorConfig, synchronous bool, stats *exporterStats) sdktrace.SpanProcessor {
+	synchronous = synchronous || batch.Synchronous
+	var processor sdktrace.SpanProcessor
+	if synchronous {
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	} else {
+		var opts []sdktrace.BatchSpanProcessorOption
+		if batch.MaxQueueSize > 0 {
+			opts = append(opts, sdktrace.WithMaxQueueSize(batch.MaxQueueSize))
+		}
+		if batch.MaxExportBatchSize > 0 {
+			opts = append(opts, sdktrace.WithMaxExportBatchSize(batch.capBatchSize()))
+		}
+		if batch.ScheduleDelay > 0 {
+			opts = append(opts, sdktrace.WithBatchTimeout(batch.ScheduleDelay))
+		}
+		if batch.ExportTimeout > 0 {
+			opts = append(opts, sdktrace.WithExportTimeout(batch.ExportTimeout))
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter, opts...)
+	}
+
+	if stats == nil {
+		return processor
+	}
+	return &countingSpanProcessor{
+		SpanProcessor: processor,
+		stats:         stats,
+		limit:         queueLimit(batch.MaxQueueSize, "OTEL_BSP_MAX_QUEUE_SIZE", synchronous),
+	}
+}
+
+// newLogProcessor creates the log processor for exporter: a simple processor
+// when synchronous, otherwise a batch processor tuned by batch. With the stats
+// of an instrumented exporter, a countingLogProcessor counts its queue.
+func newLogProcessor(exporter sdklog.Exporter, batch BatchProcessorConfig, synchronous bool, stats *exporterStats) sdklog.Processor {
+	synchronous = synchronous || batch.Synchronous
+	var processor sdklog.Processor
+	if synchronous {
+		processor = sdklog.NewSimpleProcessor(exporter)
+	} else {
+		var opts []sdklog.BatchProcessorOption
+		if batch.MaxQueueSize > 0 {
+			opts = append(opts, sdklog.WithMaxQueueSize(batch.MaxQueueSize))
+		}
+		if batch.MaxExportBatchSize > 0 {
+			opts = append(opts, sdklog.WithExportMaxBatchSize(batch.capBatchSize()))
+		}
+		if batch.ScheduleDelay > 0 {
+			opts = append(opts, sdklog.WithExportInterval(batch.ScheduleDelay))
+		}
+		if batch.ExportTimeout > 0 {
+			opts = append(opts, sdklog.WithExportTimeout(batch.ExportTimeout))
+		}
+		processor = sdklog.NewBatchProcessor(exporter, opts...)
+	}
+
+	if stats == nil {
+		return processor
+	}
+	return &countingLogProcessor{
+		Processor: processor,
+		stats:     stats,
+		limit:     queueLimit(batch.MaxQueueSize, "OTEL_BLRP_MAX_QUEUE_SIZE", synchronous),
+	}
+}
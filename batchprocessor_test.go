@@ -0,0 +1,121 @@
+package otelkit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBatchProcessorConfig(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("BatchSize", func(t *testing.T) {
+		collector := newOTLPStub(t)
+		kit, err := New(Config{
+			ServiceName:   "batching",
+			ExporterType:  ExporterOTLP,
+			OTLPEndpoint:  collector.endpoint(),
+			SampleRate:    1,
+			SpanProcessor: BatchProcessorConfig{MaxExportBatchSize: 2, ScheduleDelay: time.Hour},
+		})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		defer kit.Shutdown(ctx)
+
+		for i := 0; i < 4; i++ {
+			_, span := kit.StartSpan(ctx, "batched")
+			span.End()
+		}
+		waitFor(t, "full batches", func() bool { return collector.count("/v1/traces") == 2 })
+	})
+
+	t.Run("Synchronous", func(t *testing.T) {
+		collector := newOTLPStub(t)
+		kit, err := New(Config{
+			ServiceName:   "synchronous",
+			ExporterType:  ExporterOTLP,
+			OTLPEndpoint:  collector.endpoint(),
+			SampleRate:    1,
+			SpanProcessor: BatchProcessorConfig{Synchronous: true},
+		})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		defer kit.Shutdown(ctx)
+
+		_, span := kit.StartSpan(ctx, "immediate")
+		span.End()
+		if n := collector.count("/v1/traces"); n != 1 {
+			t.Errorf("Expected the span exported on end, got %d requests", n)
+		}
+
+		processors, err := createLogProcessors(Config{LogsExporterType: ExporterStdout, LogProcessor: BatchProcessorConfig{Synchronous: true}}, nil)
+		if err != nil || len(processors) != 1 {
+			t.Fatalf("Failed to create log processor: %v", err)
+		}
+	})
+
+	t.Run("PerExporter", func(t *testing.T) {
+		batched, immediate := newOTLPStub(t), newOTLPStub(t)
+		kit, err := New(Config{
+			ServiceName:   "per-exporter",
+			SampleRate:    1,
+			SpanProcessor: BatchProcessorConfig{MaxExportBatchSize: 2, ScheduleDelay: time.Hour},
+			TraceExporters: []ExporterConfig{
+				{Type: ExporterOTLP, Endpoint: batched.endpoint()},
+				{Type: ExporterOTLP, Endpoint: immediate.endpoint(), Processor: BatchProcessorConfig{MaxExportBatchSize: 1}},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		defer kit.Shutdown(ctx)
+
+		for i := 0; i < 4; i++ {
+			_, span := kit.StartSpan(ctx, "batched")
+			span.End()
+		}
+		waitFor(t, "per-exporter batches", func() bool {
+			return batched.count("/v1/traces") == 2 && immediate.count("/v1/traces") == 4
+		})
+
+		want := BatchProcessorConfig{MaxQueueSize: 100, MaxExportBatchSize: 1, ScheduleDelay: time.Hour, Synchronous: true}
+		got := BatchProcessorConfig{MaxQueueSize: 100, MaxExportBatchSize: 2, ScheduleDelay: time.Hour}.overriddenBy(BatchProcessorConfig{MaxExportBatchSize: 1, Synchronous: true})
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := createSpanProcessors(Config{ExporterType: ExporterStdout, SpanProcessor: BatchProcessorConfig{MaxQueueSize: -1}}, nil)
+		if err == nil {
+			t.Error("Expected an error for a negative queue size")
+		}
+		_, err = createLogProcessors(Config{LogsExporterType: ExporterStdout, LogProcessor: BatchProcessorConfig{ExportTimeout: -time.Second}}, nil)
+		if err == nil {
+			t.Error("Expected an error for a negative export timeout")
+		}
+		_, err = createSpanProcessors(Config{TraceExporters: []ExporterConfig{{Type: ExporterStdout, Processor: BatchProcessorConfig{ScheduleDelay: -time.Second}}}}, nil)
+		if err == nil {
+			t.Error("Expected an error for a negative exporter schedule delay")
+		}
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("OTEL_BSP_MAX_QUEUE_SIZE", "4096")
+		t.Setenv("OTEL_BSP_SCHEDULE_DELAY", "200")
+		t.Setenv("OTEL_BLRP_MAX_EXPORT_BATCH_SIZE", "64")
+		t.Setenv("OTEL_BLRP_EXPORT_TIMEOUT", "10000")
+
+		config := DefaultConfig()
+		want := BatchProcessorConfig{MaxQueueSize: 4096, MaxExportBatchSize: 512, ScheduleDelay: 200 * time.Millisecond, ExportTimeout: 30 * time.Second}
+		if config.SpanProcessor != want {
+			t.Errorf("Expected span processor %+v, got %+v", want, config.SpanProcessor)
+		}
+		want = BatchProcessorConfig{MaxQueueSize: 2048, MaxExportBatchSize: 64, ScheduleDelay: time.Second, ExportTimeout: 10 * time.Second}
+		if config.LogProcessor != want {
+			t.Errorf("Expected log processor %+v, got %+v", want, config.LogProcessor)
+		}
+	})
+}
@@ -0,0 +1,432 @@
+package otelkit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheResult is the outcome of a cache request, used as the result label of
+// cache_requests_total.
+type CacheResult string
+
+const (
+	// CacheHit means a read found the item
+	CacheHit CacheResult = "hit"
+
+	// CacheMiss means a read did not find the item
+	CacheMiss CacheResult = "miss"
+
+	// CacheError means the request failed
+	CacheError CacheResult = "error"
+
+	// CacheOK means a write or delete succeeded
+	CacheOK CacheResult = "ok"
+)
+
+// CacheStatus describes a completed cache request. It is returned by the
+// function passed to CacheRequest.
+//
+// Fields:
+//   - Result: CacheHit or CacheMiss for reads; left empty (CacheOK) for writes and deletes
+//   - Size: Size of the item read or written in bytes (0 if unknown)
+//   - TTL: Expiration set on the item (0 if none or unknown)
+type CacheStatus struct {
+	Result CacheResult
+	Size   int
+	TTL    time.Duration
+}
+
+// CacheOption configures cache instrumentation (CacheRequest, InstrumentCache).
+type CacheOption func(*cacheConfig)
+
+// cacheConfig collects the settings applied by CacheOption values.
+//
+// Fields:
+//   - system: The cache technology (redis, memcached, ...), recorded as cache.system
+//   - template: Explicit key template; derived with CacheKeyTemplate when empty
+//   - rawKey: Record the raw key instead of its hash
+//   - traceOpts: Span settings passed through to the span
+type cacheConfig struct {
+	system    string
+	template  string
+	rawKey    bool
+	traceOpts []TraceOption
+}
+
+// WithCacheSystem sets the cache technology recorded on spans and metrics (e.g. "redis").
+func WithCacheSystem(system string) CacheOption {
+	return func(c *cacheConfig) {
+		c.system = system
+	}
+}
+
+// WithCacheKeyTemplate records template (e.g. "user:{id}:profile") as cache.key.template
+// instead of deriving it from the key.
+func WithCacheKeyTemplate(template string) CacheOption {
+	return func(c *cacheConfig) {
+		c.template = template
+	}
+}
+
+// WithRawCacheKey records the key itself as cache.key instead of its hash.
+// Only use it when keys contain no personal data.
+func WithRawCacheKey() CacheOption {
+	return func(c *cacheConfig) {
+		c.rawKey = true
+	}
+}
+
+// WithCacheTraceOptions passes span settings (WithAttributes, WithLinks, ...)
+// through to the cache span.
+func WithCacheTraceOptions(opts ...TraceOption) CacheOption {
+	return func(c *cacheConfig) {
+		c.traceOpts = append(c.traceOpts, opts...)
+	}
+}
+
+// newCacheConfig applies the options in order; later options win.
+func newCacheConfig(opts []CacheOption) *cacheConfig {
+	cfg := &cacheConfig{system: "unknown"}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// CacheRequest traces, logs and measures a cache request with its hit/miss outcome.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - operation: The cache operation type (get, set, delete, etc.)
+//   - key: The cache key being operated on (hashed unless WithRawCacheKey is given)
+//   - fn: Performs the request and reports its result, item size and TTL
+//   - opts: Optional settings (WithCacheSystem, WithCacheKeyTemplate, WithRawCacheKey,
+//     WithCacheTraceOptions)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// The span "cache.<operation>" is created with SpanKindClient and these attributes:
+//   - cache.system: The cache technology (also recorded as db.system.name when set)
+//   - cache.operation: The operation type
+//   - cache.key: "hmac-sha256:" followed by the first 16 hex digits of the key's
+//     HMAC, keyed with Config.CacheKeyHashKey
+//   - cache.key.template: The key namespace with its values replaced, e.g. "user:{id}"
+//   - cache.result: hit, miss, error or ok
+//   - cache.hit: Whether a read found the item (reads only)
+//   - cache.item.size: Item size in bytes (when reported)
+//   - cache.ttl_seconds: Item TTL in seconds (when reported)
+//
+// Metrics, labelled with cache.system, cache.operation and result:
+//   - cache_requests_total: Counter of requests
+//   - cache_request_duration_seconds: Histogram of request latency
+//
+// Example:
+//   var user []byte
+//   err := kit.CacheRequest(ctx, "get", "user:"+id, func(ctx context.Context) (otelkit.CacheStatus, error) {
+//       v, err := rdb.Get(ctx, "user:"+id).Bytes()
+//       if errors.Is(err, redis.Nil) {
+//           return otelkit.CacheStatus{Result: otelkit.CacheMiss}, nil
+//       }
+//       user = v
+//       return otelkit.CacheStatus{Result: otelkit.CacheHit, Size: len(v)}, err
+//   }, otelkit.WithCacheSystem("redis"))
+func (o *OTelKit) CacheRequest(ctx context.Context, operation, key string, fn func(ctx context.Context) (CacheStatus, error), opts ...CacheOption) error {
+	cfg := newCacheConfig(opts)
+
+	template := cfg.template
+	if template == "" {
+		template = CacheKeyTemplate(key)
+	}
+	recordedKey := o.hashCacheKey(key)
+	if cfg.rawKey {
+		recordedKey = key
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("cache.system", cfg.system),
+		attribute.String("cache.operation", operation),
+		attribute.String("cache.key", recordedKey),
+		attribute.String("cache.key.template", template),
+	}
+	if cfg.system != "unknown" {
+		// Lets the collector's service graph draw the cache as a peer node
+		attrs = append(attrs, semconv.DBSystemNameKey.String(cfg.system))
+	}
+
+	start := time.Now()
+	var status CacheStatus
+
+	err := o.traceWithOptions(ctx, "cache."+operation, func(ctx context.Context) error {
+		var err error
+		status, err = fn(ctx)
+		if err != nil {
+			status.Result = CacheError
+		} else if status.Result == "" {
+			status.Result = CacheOK
+		}
+
+		attrs := []attribute.KeyValue{attribute.String("cache.result", string(status.Result))}
+		if status.Result == CacheHit || status.Result == CacheMiss {
+			attrs = append(attrs, attribute.Bool("cache.hit", status.Result == CacheHit))
+		}
+		if status.Size > 0 {
+			attrs = append(attrs, attribute.Int("cache.item.size", status.Size))
+		}
+		if status.TTL > 0 {
+			attrs = append(attrs, attribute.Float64("cache.ttl_seconds", status.TTL.Seconds()))
+		}
+		trace.SpanFromContext(ctx).SetAttributes(attrs...)
+		return err
+	}, append([]TraceOption{WithSpanKind(trace.SpanKindClient), WithAttributes(attrs...)}, cfg.traceOpts...)...)
+
+	duration := time.Since(start)
+
+	// Log operation completion; err also covers a panic recovered from fn
+	if err != nil {
+		status.Result = CacheError
+		o.LogError(ctx, "Cache request failed", err,
+			slog.String("operation", operation),
+			slog.String("key_template", template),
+			slog.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
+		)
+	} else {
+		o.LogDebug(ctx, "Cache request completed",
+			slog.String("operation", operation),
+			slog.String("key_template", template),
+			slog.String("result", string(status.Result)),
+			slog.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
+		)
+	}
+
+	o.recordCacheRequest(ctx, cfg.system, operation, status.Result, duration)
+	return err
+}
+
+// recordCacheRequest records one cache request in cache_requests_total and
+// cache_request_duration_seconds.
+func (o *OTelKit) recordCacheRequest(ctx context.Context, system, operation string, result CacheResult, duration time.Duration) {
+	attrs := metric.WithAttributes(
+		attribute.String("cache.system", system),
+		attribute.String("cache.operation", operation),
+		attribute.String("result", string(result)),
+	)
+	if o.cacheRequestsTotal != nil {
+		o.cacheRequestsTotal.Add(ctx, 1, attrs)
+	}
+	if o.cacheRequestDuration != nil {
+		o.cacheRequestDuration.Record(ctx, duration.Seconds(), attrs)
+	}
+}
+
+// CacheKeyTemplate replaces the value segments of a cache key with "{id}", so
+// keys can be grouped without recording their values. The leading namespace
+// ends at the first ':', '/', '.', '|' or '#' and is made of letters, '_' and
+// '-' only; otherwise the whole key is replaced. Later segments, split on ':',
+// '/', '|' and '#', are kept when they are constant words (letters, digits, '_'
+// and '-') and replaced when they are numbers, UUIDs, hex strings containing a
+// digit, or contain any other character (e.g. e-mail addresses). Pass
+// WithCacheKeyTemplate to record a more precise template.
+//
+// Parameters:
+//   - key: The cache key
+//
+// Returns:
+//   - string: The key template
+//
+// Example:
+//   otelkit.CacheKeyTemplate("user:42:profile")          // "user:{id}:profile"
+//   otelkit.CacheKeyTemplate("session:john@example.com") // "session:{id}"
+//   otelkit.CacheKeyTemplate("alice")                    // "{id}"
+func CacheKeyTemplate(key string) string {
+	if key == "" {
+		return ""
+	}
+	end := strings.IndexAny(key, ":/.|#")
+	if end < 0 || !isCacheKeyNamespace(key[:end]) {
+		return "{id}"
+	}
+
+	// Values are split on every separator but '.', which e-mail addresses and
+	// host names contain
+	var b strings.Builder
+	b.WriteString(key[:end+1])
+	segStart := end + 1
+	for i := segStart; i <= len(key); i++ {
+		if i < len(key) && !strings.ContainsRune(":/|#", rune(key[i])) {
+			continue
+		}
+		if seg := key[segStart:i]; isCacheKeyConstant(seg) {
+			b.WriteString(seg)
+		} else if seg != "" {
+			b.WriteString("{id}")
+		}
+		if i < len(key) {
+			b.WriteByte(key[i])
+		}
+		segStart = i + 1
+	}
+	return b.String()
+}
+
+// isCacheKeyNamespace reports whether a key segment looks like a constant
+// namespace: letters, '_' and '-' only.
+func isCacheKeyNamespace(seg string) bool {
+	if seg == "" {
+		return false
+	}
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// isCacheKeyConstant reports whether a segment after the namespace looks like
+// a constant rather than a value: a word of letters, digits, '_' and '-' that
+// is not a number, a UUID or a hex string with a digit.
+func isCacheKeyConstant(seg string) bool {
+	if seg == "" || isUUID(seg) {
+		return false
+	}
+	digits, hexDigits := 0, 0
+	for i := 0; i < len(seg); i++ {
+		c := seg[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+			hexDigits++
+		case c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F':
+			hexDigits++
+		case c >= 'g' && c <= 'z' || c >= 'G' && c <= 'Z' || c == '_' || c == '-':
+		default:
+			return false
+		}
+	}
+	return digits == 0 || hexDigits < len(seg)
+}
+
+// isUUID reports whether s is a UUID in its canonical 8-4-4-4-12 form.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexDigit(s[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// processCacheKeyHashKey keys the cache key HMAC when Config.CacheKeyHashKey is empty.
+var processCacheKeyHashKey = func() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(fmt.Sprintf("otelkit: generating cache key hash key: %v", err))
+	}
+	return key
+}()
+
+// hashCacheKey returns a short, keyed digest of key for correlating requests
+// on the same key without recording it. The HMAC key keeps low-entropy keys
+// such as "user:42" from being recovered by hashing guesses.
+func (o *OTelKit) hashCacheKey(key string) string {
+	secret := processCacheKeyHashKey
+	if o.config.CacheKeyHashKey != "" {
+		secret = []byte(o.config.CacheKeyHashKey)
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(key))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Cache is the minimal interface InstrumentCache wraps. Adapt an existing
+// client (Redis, Memcached, an in-process LRU) by implementing these methods.
+type Cache interface {
+	// Get returns the value stored under key and whether it was found.
+	// A miss is reported with found == false and a nil error.
+	Get(ctx context.Context, key string) (value []byte, found bool, err error)
+
+	// Set stores value under key. A ttl of 0 means no expiration.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete removes key.
+	Delete(ctx context.Context, key string) error
+}
+
+// InstrumentCache wraps c so every Get, Set and Delete is traced and measured
+// with CacheRequest.
+//
+// Parameters:
+//   - c: The cache to instrument
+//   - opts: Optional settings applied to every request (e.g., WithCacheSystem)
+//
+// Returns:
+//   - Cache: A cache that records hit/miss outcome, item size and TTL
+//
+// Example:
+//   cache := kit.InstrumentCache(redisAdapter{rdb}, otelkit.WithCacheSystem("redis"))
+//   value, found, err := cache.Get(ctx, "user:42")
+func (o *OTelKit) InstrumentCache(c Cache, opts ...CacheOption) Cache {
+	return &instrumentedCache{cache: c, kit: o, opts: opts}
+}
+
+// instrumentedCache implements Cache by delegating to the wrapped cache under CacheRequest.
+type instrumentedCache struct {
+	cache Cache
+	kit   *OTelKit
+	opts  []CacheOption
+}
+
+// Get implements Cache.
+func (c *instrumentedCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var value []byte
+	var found bool
+	err := c.kit.CacheRequest(ctx, "get", key, func(ctx context.Context) (CacheStatus, error) {
+		var err error
+		value, found, err = c.cache.Get(ctx, key)
+		if !found {
+			return CacheStatus{Result: CacheMiss}, err
+		}
+		return CacheStatus{Result: CacheHit, Size: len(value)}, err
+	}, c.opts...)
+	return value, found, err
+}
+
+// Set implements Cache.
+func (c *instrumentedCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.kit.CacheRequest(ctx, "set", key, func(ctx context.Context) (CacheStatus, error) {
+		return CacheStatus{Size: len(value), TTL: ttl}, c.cache.Set(ctx, key, value, ttl)
+	}, c.opts...)
+}
+
+// Delete implements Cache.
+func (c *instrumentedCache) Delete(ctx context.Context, key string) error {
+	return c.kit.CacheRequest(ctx, "delete", key, func(ctx context.Context) (CacheStatus, error) {
+		return CacheStatus{}, c.cache.Delete(ctx, key)
+	}, c.opts...)
+}
@@ -0,0 +1,196 @@
+package otelkit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// mapCache is an in-memory Cache; keys containing "down" fail.
+type mapCache struct {
+	mu    sync.Mutex
+	items map[string][]byte
+}
+
+func (c *mapCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	if key == "down" {
+		return nil, false, errors.New("cache unavailable")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.items[key]
+	return value, ok, nil
+}
+
+func (c *mapCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items[key] = value
+	return nil
+}
+
+func (c *mapCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.items, key)
+	return nil
+}
+
+func TestInstrumentCache(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "cache-test"})
+	reader := sdkmetric.NewManualReader()
+	if err := kit.initMetricsInstruments(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("otelkit-test")); err != nil {
+		t.Fatalf("initMetricsInstruments failed: %v", err)
+	}
+
+	cache := kit.InstrumentCache(&mapCache{items: map[string][]byte{}}, WithCacheSystem("memory"))
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "user:42:profile", []byte("alice"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, found, _ := cache.Get(ctx, "user:42:profile"); !found {
+		t.Error("Expected hit")
+	}
+	if _, found, _ := cache.Get(ctx, "user:43:profile"); found {
+		t.Error("Expected miss")
+	}
+	if _, _, err := cache.Get(ctx, "down"); err == nil {
+		t.Error("Expected error")
+	}
+
+	t.Run("Spans", func(t *testing.T) {
+		spans := recorder.Ended()
+		if len(spans) != 4 {
+			t.Fatalf("Expected 4 spans, got %d", len(spans))
+		}
+
+		set := attributeMap(spans[0].Attributes())
+		if set["cache.result"] != "ok" || set["cache.item.size"] != "5" || set["cache.ttl_seconds"] != "60" {
+			t.Errorf("Unexpected set attributes: %v", set)
+		}
+
+		hit := attributeMap(spans[1].Attributes())
+		if hit["cache.result"] != "hit" || hit["cache.hit"] != "true" || hit["cache.system"] != "memory" {
+			t.Errorf("Unexpected hit attributes: %v", hit)
+		}
+		if hit["cache.key"] != kit.hashCacheKey("user:42:profile") || hit["cache.key.template"] != "user:{id}:profile" {
+			t.Errorf("Key not hashed and templated: %v", hit)
+		}
+
+		miss := attributeMap(spans[2].Attributes())
+		if miss["cache.result"] != "miss" || miss["cache.hit"] != "false" {
+			t.Errorf("Unexpected miss attributes: %v", miss)
+		}
+
+		if failed := attributeMap(spans[3].Attributes()); failed["cache.result"] != "error" {
+			t.Errorf("Unexpected error attributes: %v", failed)
+		}
+	})
+
+	t.Run("Metrics", func(t *testing.T) {
+		counts := map[string]int64{}
+		var latencyPoints int
+		for _, m := range collectMetrics(t, reader) {
+			switch m.Name {
+			case "cache_requests_total":
+				for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+					result, _ := dp.Attributes.Value("result")
+					counts[result.AsString()] += dp.Value
+				}
+			case "cache_request_duration_seconds":
+				latencyPoints = len(m.Data.(metricdata.Histogram[float64]).DataPoints)
+			}
+		}
+
+		expected := map[string]int64{"ok": 1, "hit": 1, "miss": 1, "error": 1}
+		for result, want := range expected {
+			if counts[result] != want {
+				t.Errorf("cache_requests_total{result=%q}: expected %d, got %d", result, want, counts[result])
+			}
+		}
+		if latencyPoints != 4 {
+			t.Errorf("Expected 4 latency series, got %d", latencyPoints)
+		}
+	})
+}
+
+func TestCacheRequestRawKey(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "cache-test"})
+
+	kit.CacheRequest(context.Background(), "get", "config:feature-flags", func(ctx context.Context) (CacheStatus, error) {
+		return CacheStatus{Result: CacheHit}, nil
+	}, WithRawCacheKey(), WithCacheTraceOptions(WithAttributes(attribute.String("tenant", "acme"))))
+
+	attrs := attributeMap(recorder.Ended()[0].Attributes())
+	if attrs["cache.key"] != "config:feature-flags" || attrs["tenant"] != "acme" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+	if _, ok := attrs["db.system.name"]; ok {
+		t.Error("db.system.name must not be set without a cache system")
+	}
+}
+
+func TestCacheKeyTemplate(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"user:42:profile", "user:{id}:profile"},
+		{"user:42:settings:theme", "user:{id}:settings:theme"},
+		{"user:alice", "user:alice"},
+		{"session:john@example.com", "session:{id}"},
+		{"session/6f1c2d3e-8a9b-4c5d-9e0f-1a2b3c4d5e6f", "session/{id}"},
+		{"session/ABCDEFAB-ABCD-ABCD-ABCD-ABCDEFABCDEF", "session/{id}"},
+		{"orders.2024.summary", "orders.{id}"},
+		{"blob#a1b2c3d4e5", "blob#{id}"},
+		{"api:v2:users", "api:v2:users"},
+		{"tenant:acme|user:77", "tenant:acme|user:{id}"},
+		{"feature:", "feature:"},
+		{"alice", "{id}"},
+		{"alice@example.com", "{id}"},
+		{"user42:profile", "{id}"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := CacheKeyTemplate(tt.key); got != tt.expected {
+			t.Errorf("CacheKeyTemplate(%q): expected %q, got %q", tt.key, tt.expected, got)
+		}
+	}
+}
+
+func TestHashCacheKey(t *testing.T) {
+	kit := &OTelKit{}
+	hashed := kit.hashCacheKey("user:42")
+	if !strings.HasPrefix(hashed, "hmac-sha256:") || len(hashed) != len("hmac-sha256:")+16 {
+		t.Fatalf("Unexpected hash format: %q", hashed)
+	}
+	if kit.hashCacheKey("user:42") != hashed {
+		t.Error("Expected a stable hash within the process")
+	}
+
+	// An unkeyed digest would let anyone hash guesses like "user:42"
+	unkeyed := sha256.Sum256([]byte("user:42"))
+	if hashed == "hmac-sha256:"+hex.EncodeToString(unkeyed[:8]) {
+		t.Error("Expected the hash to be keyed")
+	}
+
+	shared := &OTelKit{config: Config{CacheKeyHashKey: "secret"}}
+	other := &OTelKit{config: Config{CacheKeyHashKey: "secret"}}
+	if shared.hashCacheKey("user:42") != other.hashCacheKey("user:42") {
+		t.Error("Expected kits sharing CacheKeyHashKey to agree")
+	}
+	if shared.hashCacheKey("user:42") == hashed {
+		t.Error("Expected CacheKeyHashKey to replace the process key")
+	}
+}
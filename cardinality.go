@@ -0,0 +1,302 @@
+package otelkit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// defaultMetricCardinalityLimit is used when Config.MetricCardinalityLimit is 0;
+// it matches the OpenTelemetry specification's default.
+const defaultMetricCardinalityLimit = 2000
+
+// overflowAttributes is the attribute set that measurements beyond an
+// instrument's cardinality limit are recorded under.
+var overflowAttributes = attribute.NewSet(attribute.Bool("otel.metric.overflow", true))
+
+// cardinalityLimiter caps the number of distinct attribute sets recorded per
+// instrument. Measurements with a new attribute set beyond the limit are
+// recorded under otel.metric.overflow=true instead, so one unbounded label
+// cannot explode the number of series.
+//
+// Fields:
+//   - kit: Used to log the first overflow of each instrument
+//   - defaultLimit: Limit of instruments without their own (negative disables limiting)
+//   - limits: Per-instrument limits by instrument name
+//   - overflows: The otelkit_metric_cardinality_overflow_total self-metric
+//   - meterName: Name of the limited meter, matched against MetricView.Meter
+//   - views: Metric views whose attribute filters apply before series are counted
+type cardinalityLimiter struct {
+	kit          *OTelKit
+	defaultLimit int
+	overflows    metric.Int64Counter
+	meterName    string
+	views        []MetricView
+
+	mu     sync.Mutex
+	limits map[string]int
+}
+
+// newCardinalityLimiter creates the limiter from the cardinality settings of
+// config for the meter named config.ServiceName, counting the attribute sets
+// that views keep; the overflow self-metric is created with meter.
+func newCardinalityLimiter(kit *OTelKit, config Config, meter metric.Meter, views []MetricView) (*cardinalityLimiter, error) {
+	defaultLimit := config.MetricCardinalityLimit
+	if defaultLimit == 0 {
+		defaultLimit = defaultMetricCardinalityLimit
+	}
+
+	limits := make(map[string]int, len(config.MetricCardinalityLimits))
+	for name, limit := range config.MetricCardinalityLimits {
+		limits[name] = limit
+	}
+
+	overflows, err := meter.Int64Counter(
+		"otelkit_metric_cardinality_overflow_total",
+		metric.WithDescription("Measurements recorded under otel.metric.overflow because their instrument reached its cardinality limit"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cardinalityLimiter{
+		kit:          kit,
+		defaultLimit: defaultLimit,
+		overflows:    overflows,
+		meterName:    config.ServiceName,
+		views:        views,
+		limits:       limits,
+	}, nil
+}
+
+// setLimit sets the limit of one instrument, unless Config.MetricCardinalityLimits
+// already does; it must be called before the instrument is created.
+func (l *cardinalityLimiter) setLimit(name string, limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.limits[name]; !ok {
+		l.limits[name] = limit
+	}
+}
+
+// forInstrument returns the series tracker of a new instrument, or nil if it is not limited.
+func (l *cardinalityLimiter) forInstrument(name string) *seriesLimit {
+	l.mu.Lock()
+	limit, ok := l.limits[name]
+	l.mu.Unlock()
+	if !ok {
+		limit = l.defaultLimit
+	}
+	if limit < 0 {
+		return nil
+	}
+	return &seriesLimit{
+		limiter: l,
+		name:    name,
+		limit:   max(limit, 1),
+		filter:  viewAttributeFilter(l.views, l.meterName, name),
+		seen:    make(map[attribute.Distinct]struct{}),
+	}
+}
+
+// seriesLimit tracks the attribute sets recorded by one instrument, as left
+// by the attribute filter of its views.
+type seriesLimit struct {
+	limiter *cardinalityLimiter
+	name    string
+	limit   int
+	filter  attribute.Filter
+
+	mu         sync.RWMutex
+	seen       map[attribute.Distinct]struct{}
+	overflowed bool
+}
+
+// allow reports whether attrs may be recorded as is: it was seen before, or
+// there is still room for a new series. The overflow series itself counts as
+// one of the limit's series, as in the OpenTelemetry SDK.
+func (s *seriesLimit) allow(ctx context.Context, attrs attribute.Set) bool {
+	if s.filter != nil {
+		attrs, _ = attrs.Filter(s.filter)
+	}
+	key := attrs.Equivalent()
+
+	s.mu.RLock()
+	_, ok := s.seen[key]
+	s.mu.RUnlock()
+	if ok {
+		return true
+	}
+
+	s.mu.Lock()
+	if _, ok := s.seen[key]; ok {
+		s.mu.Unlock()
+		return true
+	}
+	if len(s.seen) < s.limit-1 {
+		s.seen[key] = struct{}{}
+		s.mu.Unlock()
+		return true
+	}
+	first := !s.overflowed
+	s.overflowed = true
+	s.mu.Unlock()
+
+	s.limiter.overflows.Add(ctx, 1, metric.WithAttributes(attribute.String("instrument", s.name)))
+	if first {
+		s.limiter.kit.LogWarn(ctx, "Metric cardinality limit reached, new series are recorded under otel.metric.overflow=true",
+			slog.String("instrument", s.name),
+			slog.Int("limit", s.limit),
+		)
+	}
+	return false
+}
+
+// addOptions returns opts with the attributes replaced by the overflow set
+// when they exceed the limit.
+func (s *seriesLimit) addOptions(ctx context.Context, opts []metric.AddOption) []metric.AddOption {
+	if s == nil || s.allow(ctx, metric.NewAddConfig(opts).Attributes()) {
+		return opts
+	}
+	return []metric.AddOption{metric.WithAttributeSet(overflowAttributes)}
+}
+
+// recordOptions is addOptions for histograms and gauges.
+func (s *seriesLimit) recordOptions(ctx context.Context, opts []metric.RecordOption) []metric.RecordOption {
+	if s == nil || s.allow(ctx, metric.NewRecordConfig(opts).Attributes()) {
+		return opts
+	}
+	return []metric.RecordOption{metric.WithAttributeSet(overflowAttributes)}
+}
+
+// limitingMeter is a metric.Meter whose synchronous instruments apply the
+// cardinality limiter. Observable instruments are passed through unchanged:
+// their callbacks decide which series exist.
+type limitingMeter struct {
+	metric.Meter
+	limiter *cardinalityLimiter
+}
+
+// Int64Counter implements metric.Meter.
+func (m limitingMeter) Int64Counter(name string, options ...metric.Int64CounterOption) (metric.Int64Counter, error) {
+	inst, err := m.Meter.Int64Counter(name, options...)
+	return limitedInt64Counter{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Float64Counter implements metric.Meter.
+func (m limitingMeter) Float64Counter(name string, options ...metric.Float64CounterOption) (metric.Float64Counter, error) {
+	inst, err := m.Meter.Float64Counter(name, options...)
+	return limitedFloat64Counter{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Int64UpDownCounter implements metric.Meter.
+func (m limitingMeter) Int64UpDownCounter(name string, options ...metric.Int64UpDownCounterOption) (metric.Int64UpDownCounter, error) {
+	inst, err := m.Meter.Int64UpDownCounter(name, options...)
+	return limitedInt64UpDownCounter{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Float64UpDownCounter implements metric.Meter.
+func (m limitingMeter) Float64UpDownCounter(name string, options ...metric.Float64UpDownCounterOption) (metric.Float64UpDownCounter, error) {
+	inst, err := m.Meter.Float64UpDownCounter(name, options...)
+	return limitedFloat64UpDownCounter{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Int64Histogram implements metric.Meter.
+func (m limitingMeter) Int64Histogram(name string, options ...metric.Int64HistogramOption) (metric.Int64Histogram, error) {
+	inst, err := m.Meter.Int64Histogram(name, options...)
+	return limitedInt64Histogram{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Float64Histogram implements metric.Meter.
+func (m limitingMeter) Float64Histogram(name string, options ...metric.Float64HistogramOption) (metric.Float64Histogram, error) {
+	inst, err := m.Meter.Float64Histogram(name, options...)
+	return limitedFloat64Histogram{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Int64Gauge implements metric.Meter.
+func (m limitingMeter) Int64Gauge(name string, options ...metric.Int64GaugeOption) (metric.Int64Gauge, error) {
+	inst, err := m.Meter.Int64Gauge(name, options...)
+	return limitedInt64Gauge{inst, m.limiter.forInstrument(name)}, err
+}
+
+// Float64Gauge implements metric.Meter.
+func (m limitingMeter) Float64Gauge(name string, options ...metric.Float64GaugeOption) (metric.Float64Gauge, error) {
+	inst, err := m.Meter.Float64Gauge(name, options...)
+	return limitedFloat64Gauge{inst, m.limiter.forInstrument(name)}, err
+}
+
+type limitedInt64Counter struct {
+	metric.Int64Counter
+	limit *seriesLimit
+}
+
+func (c limitedInt64Counter) Add(ctx context.Context, incr int64, options ...metric.AddOption) {
+	c.Int64Counter.Add(ctx, incr, c.limit.addOptions(ctx, options)...)
+}
+
+type limitedFloat64Counter struct {
+	metric.Float64Counter
+	limit *seriesLimit
+}
+
+func (c limitedFloat64Counter) Add(ctx context.Context, incr float64, options ...metric.AddOption) {
+	c.Float64Counter.Add(ctx, incr, c.limit.addOptions(ctx, options)...)
+}
+
+type limitedInt64UpDownCounter struct {
+	metric.Int64UpDownCounter
+	limit *seriesLimit
+}
+
+func (c limitedInt64UpDownCounter) Add(ctx context.Context, incr int64, options ...metric.AddOption) {
+	c.Int64UpDownCounter.Add(ctx, incr, c.limit.addOptions(ctx, options)...)
+}
+
+type limitedFloat64UpDownCounter struct {
+	metric.Float64UpDownCounter
+	limit *seriesLimit
+}
+
+func (c limitedFloat64UpDownCounter) Add(ctx context.Context, incr float64, options ...metric.AddOption) {
+	c.Float64UpDownCounter.Add(ctx, incr, c.limit.addOptions(ctx, options)...)
+}
+
+type limitedInt64Histogram struct {
+	metric.Int64Histogram
+	limit *seriesLimit
+}
+
+func (h limitedInt64Histogram) Record(ctx context.Context, value int64, options ...metric.RecordOption) {
+	h.Int64Histogram.Record(ctx, value, h.limit.recordOptions(ctx, options)...)
+}
+
+type limitedFloat64Histogram struct {
+	metric.Float64Histogram
+	limit *seriesLimit
+}
+
+func (h limitedFloat64Histogram) Record(ctx context.Context, value float64, options ...metric.RecordOption) {
+	h.Float64Histogram.Record(ctx, value, h.limit.recordOptions(ctx, options)...)
+}
+
+type limitedInt64Gauge struct {
+	metric.Int64Gauge
+	limit *seriesLimit
+}
+
+func (g limitedInt64Gauge) Record(ctx context.Context, value int64, options ...metric.RecordOption) {
+	g.Int64Gauge.Record(ctx, value, g.limit.recordOptions(ctx, options)...)
+}
+
+type limitedFloat64Gauge struct {
+	metric.Float64Gauge
+	limit *seriesLimit
+}
+
+func (g limitedFloat64Gauge) Record(ctx context.Context, value float64, options ...metric.RecordOption) {
+	g.Float64Gauge.Record(ctx, value, g.limit.recordOptions(ctx, options)...)
+}
@@ -0,0 +1,192 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newCardinalityKit returns a kit whose meter applies the cardinality limits of
+// config, the reader collecting its metrics and the buffer receiving its logs.
+func newCardinalityKit(t *testing.T, config Config) (*OTelKit, *sdkmetric.ManualReader, *syncBuffer) {
+	t.Helper()
+
+	kit, reader := newMetricsKit(t, config)
+	logs := &syncBuffer{}
+	kit.logger = slog.New(slog.NewJSONHandler(logs, nil))
+	return kit, reader, logs
+}
+
+// sumSeries returns the data points of an int64 sum by encoded attribute set.
+func sumSeries(t *testing.T, reader *sdkmetric.ManualReader, name string) map[string]int64 {
+	t.Helper()
+	series := make(map[string]int64)
+	for _, dp := range findMetric(t, reader, name).Data.(metricdata.Sum[int64]).DataPoints {
+		series[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Value
+	}
+	return series
+}
+
+func TestCardinalityLimit(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("GlobalLimit", func(t *testing.T) {
+		kit, reader, logs := newCardinalityKit(t, Config{MetricCardinalityLimit: 4})
+
+		for i := 0; i < 10; i++ {
+			kit.RecordMetric(ctx, "checkout", 1, attribute.String("user.id", fmt.Sprint(i)))
+		}
+		kit.RecordMetric(ctx, "checkout", 1, attribute.String("user.id", "0"))
+
+		ops := sumSeries(t, reader, "otelkit_business_operations_total")
+		if len(ops) != 4 {
+			t.Errorf("Expected 3 series plus overflow, got %v", ops)
+		}
+		if n := ops["otel.metric.overflow=true"]; n != 7 {
+			t.Errorf("Expected 7 overflowed measurements, got %d", n)
+		}
+		if n := ops["operation_type=checkout,user.id=0"]; n != 2 {
+			t.Errorf("Expected a known series to keep recording, got %d", n)
+		}
+
+		overflows := sumSeries(t, reader, "otelkit_metric_cardinality_overflow_total")
+		if n := overflows["instrument=otelkit_business_operations_total"]; n != 7 {
+			t.Errorf("Expected 7 counted overflows, got %v", overflows)
+		}
+		if strings.Count(logs.String(), "Metric cardinality limit reached") != 1 {
+			t.Errorf("Expected one overflow warning, got:\n%s", logs.String())
+		}
+	})
+
+	t.Run("HTTPMetrics", func(t *testing.T) {
+		kit, reader, _ := newCardinalityKit(t, Config{MetricCardinalityLimits: map[string]int{"http_requests_total": 2}})
+
+		for i := 0; i < 5; i++ {
+			kit.RecordHTTPMetrics(ctx, "GET", fmt.Sprint(200+i), 0)
+		}
+
+		requests := sumSeries(t, reader, "http_requests_total")
+		if len(requests) != 2 || requests["otel.metric.overflow=true"] != 4 {
+			t.Errorf("Expected 1 series plus 4 overflowed requests, got %v", requests)
+		}
+		points := findMetric(t, reader, "http_request_duration_seconds").Data.(metricdata.Histogram[float64]).DataPoints
+		if len(points) != 5 {
+			t.Errorf("Expected the duration histogram to keep the default limit, got %d series", len(points))
+		}
+	})
+
+	t.Run("PerInstrument", func(t *testing.T) {
+		kit, reader, _ := newCardinalityKit(t, Config{
+			MetricCardinalityLimit:  2,
+			MetricCardinalityLimits: map[string]int{"configured_total": 3},
+		})
+
+		unlimited, err := kit.Counter("unlimited_total", WithCardinalityLimit(-1))
+		if err != nil {
+			t.Fatalf("Failed to create counter: %v", err)
+		}
+		configured, err := kit.Counter("configured_total", WithCardinalityLimit(-1))
+		if err != nil {
+			t.Fatalf("Failed to create counter: %v", err)
+		}
+		meterCounter, err := kit.GetMeter().Int64Counter("meter_total")
+		if err != nil {
+			t.Fatalf("Failed to create counter: %v", err)
+		}
+		for i := 0; i < 5; i++ {
+			attr := attribute.Int("i", i)
+			unlimited.Inc(ctx, attr)
+			configured.Inc(ctx, attr)
+			meterCounter.Add(ctx, 1, metric.WithAttributes(attr))
+		}
+
+		if n := len(sumSeries(t, reader, "unlimited_total")); n != 5 {
+			t.Errorf("Expected WithCardinalityLimit(-1) to disable the limit, got %d series", n)
+		}
+		if n := len(sumSeries(t, reader, "configured_total")); n != 3 {
+			t.Errorf("Expected Config.MetricCardinalityLimits to take precedence, got %d series", n)
+		}
+		if n := len(sumSeries(t, reader, "meter_total")); n != 2 {
+			t.Errorf("Expected GetMeter instruments to use the global limit, got %d series", n)
+		}
+	})
+
+	t.Run("ViewAttributeFilter", func(t *testing.T) {
+		kit, reader, _ := newCardinalityKit(t, Config{
+			ServiceName:            "cardinality-test",
+			MetricCardinalityLimit: 3,
+			MetricViews: []MetricView{
+				{Instrument: "otelkit_business_*", ExcludeAttributeKeys: []string{"user.id"}},
+				{Instrument: "http_requests_total", Meter: "other-meter", AttributeKeys: []string{"method"}},
+			},
+		})
+
+		for i := 0; i < 10; i++ {
+			kit.RecordMetric(ctx, "checkout", 1, attribute.String("user.id", fmt.Sprint(i)))
+			kit.RecordHTTPMetrics(ctx, "GET", fmt.Sprint(200+i), 0)
+		}
+
+		ops := sumSeries(t, reader, "otelkit_business_operations_total")
+		if len(ops) != 1 || ops["operation_type=checkout"] != 10 {
+			t.Errorf("Expected the filtered series to stay under the limit, got %v", ops)
+		}
+		requests := sumSeries(t, reader, "http_requests_total")
+		if len(requests) != 3 || requests["otel.metric.overflow=true"] != 8 {
+			t.Errorf("Expected a view of another meter not to apply, got %v", requests)
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		kit, reader, _ := newCardinalityKit(t, Config{MetricCardinalityLimit: 10})
+		counter, err := kit.Counter("concurrent_total")
+		if err != nil {
+			t.Fatalf("Failed to create counter: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 100; i++ {
+					counter.Inc(ctx, attribute.Int("i", (g*100+i)%50))
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		series := sumSeries(t, reader, "concurrent_total")
+		var total int64
+		for _, n := range series {
+			total += n
+		}
+		if len(series) != 10 || total != 800 {
+			t.Errorf("Expected 10 series totalling 800, got %d series totalling %d", len(series), total)
+		}
+	})
+}
+
+func BenchmarkCardinalityLimit(b *testing.B) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("bench")
+	limiter, err := newCardinalityLimiter(&OTelKit{}, Config{}, meter, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	counter, _ := limitingMeter{Meter: meter, limiter: limiter}.Int64Counter("bench_total")
+	opt := metric.WithAttributes(attribute.String("method", "GET"), attribute.Int("status_code", 200))
+	ctx := context.Background()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		counter.Add(ctx, 1, opt)
+	}
+}
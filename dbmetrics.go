@@ -0,0 +1,180 @@
+package otelkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// RegisterDBPoolMetrics registers observable connection pool metrics for db.
+// OpenDB calls this automatically; use it directly for pools opened with
+// sql.Open or sql.OpenDB.
+//
+// Parameters:
+//   - db: The connection pool to observe
+//   - opts: Optional settings (WithDBSystem, WithDBNamespace) used to label the pool
+//
+// Returns:
+//   - metric.Registration: Unregister it to stop observing db (e.g. after db.Close)
+//   - error: Any error creating the instruments or registering the callback
+//
+// Metrics (from sql.DBStats, observed at collection time):
+//   - db.client.connection.count: Connections by state (idle, used); open connections
+//     are the sum of both states
+//   - db.client.connection.max: Maximum open connections allowed
+//   - db.client.connection.wait_count: Total connections waited for
+//   - db.client.connection.wait_time: Total time blocked waiting for a connection
+//
+// Every metric carries db.system and db.client.connection.pool.name attributes.
+//
+// Example:
+//   db, _ := sql.Open("postgres", dsn)
+//   reg, err := kit.RegisterDBPoolMetrics(db, otelkit.WithDBSystem("postgresql"), otelkit.WithDBNamespace("orders"))
+//   defer reg.Unregister()
+func (o *OTelKit) RegisterDBPoolMetrics(db *sql.DB, opts ...SQLOption) (metric.Registration, error) {
+	config := sqlConfig{system: "other_sql"}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return o.registerDBPoolMetrics(db, config)
+}
+
+// registerDBPoolMetrics registers observable gauges fed from db.Stats().
+// Without a meter the callback is registered with a no-op meter so callers
+// always get a usable Registration.
+func (o *OTelKit) registerDBPoolMetrics(db *sql.DB, config sqlConfig) (metric.Registration, error) {
+	meter := o.meter
+	if meter == nil {
+		meter = noop.NewMeterProvider().Meter("otelkit")
+	}
+
+	connCount, err := meter.Int64ObservableGauge("db.client.connection.count",
+		metric.WithDescription("Number of connections currently in the pool, by state"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	connMax, err := meter.Int64ObservableGauge("db.client.connection.max",
+		metric.WithDescription("Maximum number of open connections allowed"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitCount, err := meter.Int64ObservableCounter("db.client.connection.wait_count",
+		metric.WithDescription("Total number of connections waited for"),
+		metric.WithUnit("{connection}"),
+	)
+	if err != nil {
+		return nil, err
+	}
+	waitTime, err := meter.Float64ObservableCounter("db.client.connection.wait_time",
+		metric.WithDescription("Total time blocked waiting for a new connection"),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	poolAttrs := []attribute.KeyValue{
+		attribute.String("db.system", config.system),
+		attribute.String("db.client.connection.pool.name", config.poolName()),
+	}
+	idleAttrs := metric.WithAttributes(append(poolAttrs, attribute.String("db.client.connection.state", "idle"))...)
+	usedAttrs := metric.WithAttributes(append(poolAttrs, attribute.String("db.client.connection.state", "used"))...)
+	attrs := metric.WithAttributes(poolAttrs...)
+
+	return meter.RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		stats := db.Stats()
+		observer.ObserveInt64(connCount, int64(stats.Idle), idleAttrs)
+		observer.ObserveInt64(connCount, int64(stats.InUse), usedAttrs)
+		observer.ObserveInt64(connMax, int64(stats.MaxOpenConnections), attrs)
+		observer.ObserveInt64(waitCount, stats.WaitCount, attrs)
+		observer.ObserveFloat64(waitTime, stats.WaitDuration.Seconds(), attrs)
+		return nil
+	}, connCount, connMax, waitCount, waitTime)
+}
+
+// recordDBOperation records one database operation in the db.client.operation.duration histogram.
+//
+// Parameters:
+//   - ctx: Context for the measurement (carries the span for exemplars)
+//   - system: The db.system.name value (e.g., postgresql, mysql)
+//   - operation: The operation name (SELECT, INSERT, BEGIN, etc.); omitted when empty
+//   - table: The table operated on; omitted when empty
+//   - duration: Time spent on the operation
+//   - err: The error returned by the operation, classified by dbErrorType
+func (o *OTelKit) recordDBOperation(ctx context.Context, system, operation, table string, duration time.Duration, err error) {
+	if o.dbOperationDuration == nil {
+		return
+	}
+
+	attrs := []attribute.KeyValue{semconv.DBSystemNameKey.String(system)}
+	if operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(operation))
+	}
+	if table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(table))
+	}
+	if err != nil {
+		attrs = append(attrs, semconv.ErrorTypeKey.String(dbErrorType(err)))
+	}
+	o.dbOperationDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// dbErrorType classifies a database error into a low-cardinality error.type value.
+//
+// Parameters:
+//   - err: The error returned by the operation
+//
+// Returns:
+//   - string: One of timeout, canceled, no_rows, connection, tx_done, panic,
+//     or the Go type of the error (e.g. *pq.Error) when it is not recognized
+func dbErrorType(err error) string {
+	var netErr net.Error
+	var panicErr *PanicError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, sql.ErrNoRows):
+		return "no_rows"
+	case errors.Is(err, sql.ErrConnDone), errors.Is(err, driver.ErrBadConn):
+		return "connection"
+	case errors.Is(err, sql.ErrTxDone):
+		return "tx_done"
+	case errors.As(err, &panicErr):
+		return "panic"
+	default:
+		return fmt.Sprintf("%T", err)
+	}
+}
+
+// dbSystemFromOptions finds the database system among the attributes passed with
+// WithAttributes, preferring db.system.name over the older db.system.
+func dbSystemFromOptions(opts []TraceOption) string {
+	system := "unknown"
+	for _, kv := range newTraceConfig(opts).attrs {
+		switch kv.Key {
+		case semconv.DBSystemNameKey:
+			return kv.Value.Emit()
+		case "db.system":
+			system = kv.Value.Emit()
+		}
+	}
+	return system
+}
@@ -62,12 +62,13 @@ func (s *UserService) GetUser(ctx context.Context, userID int) (*User, error) {
 	
 	s.kit.SetAttributes(ctx, attribute.Int("user.id", userID))
 
-	// Simulate database operation
-	err := s.kit.DatabaseOperation(ctx, "SELECT", "users", func(ctx context.Context) error {
+	// Simulate database operation. Passing the full statement derives the
+	// operation and table and records db.query.text with the id masked:
+	// "SELECT * FROM users WHERE id = ?"
+	query := fmt.Sprintf("SELECT * FROM users WHERE id = %d", userID)
+	err := s.kit.DatabaseOperation(ctx, query, "", func(ctx context.Context) error {
 		s.kit.SetAttributes(ctx, attribute.String("db.type", "memory"))
 
-		s.kit.AddEvent(ctx, "query_start", attribute.String("query", "SELECT * FROM users WHERE id = ?"))
-
 		// Simulate database access time
 		time.Sleep(5 * time.Millisecond)
 
@@ -106,12 +107,12 @@ func (s *UserService) CreateUser(ctx context.Context, name, email string) (*User
 		attribute.String("user.email", email),
 	)
 
-	var newUser *User
-	err := s.kit.DatabaseOperation(ctx, "INSERT", "users", func(ctx context.Context) error {
+	// The name and email never reach the span: db.query.text is
+	// "INSERT INTO users (name, email, created_at) VALUES (?, ?, NOW())"
+	query := fmt.Sprintf("INSERT INTO users (name, email, created_at) VALUES ('%s', '%s', NOW())", name, email)
+	newUser, err := otelkit.TraceDatabase(s.kit, ctx, query, "", func(ctx context.Context) (*User, error) {
 		s.kit.SetAttributes(ctx, attribute.String("db.type", "memory"))
 
-		s.kit.AddEvent(ctx, "insert_start", attribute.String("query", "INSERT INTO users (name, email, created_at) VALUES (?, ?, ?)"))
-
 		// Simulate database insert time
 		time.Sleep(10 * time.Millisecond)
 
@@ -119,18 +120,18 @@ func (s *UserService) CreateUser(ctx context.Context, name, email string) (*User
 		userID := s.nextID
 		s.nextID++
 		
-		newUser = &User{
+		user := &User{
 			ID:       userID,
 			Name:     name,
 			Email:    email,
 			CreateAt: time.Now(),
 		}
 		
-		s.users[userID] = newUser
+		s.users[userID] = user
 		s.mutex.Unlock()
 
 		s.kit.AddEvent(ctx, "user_created", attribute.Int("user.id", userID))
-		return nil
+		return user, nil
 	})
 
 	if err != nil {
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/knappmi/otelkit"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// message is what travels through the queue: a body plus headers carrying
+// the producer's trace context.
+type message struct {
+	Body    string
+	Headers otelkit.MessageHeaders
+}
+
+// queue is an in-memory stand-in for a broker such as RabbitMQ or SQS.
+type queue struct {
+	mu       sync.Mutex
+	messages []message
+}
+
+func (q *queue) Send(msg message) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.messages = append(q.messages, msg)
+}
+
+func (q *queue) Receive(max int) []message {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(max, len(q.messages))
+	batch := q.messages[:n]
+	q.messages = q.messages[n:]
+	return batch
+}
+
+func main() {
+	config := otelkit.DefaultConfig()
+	config.ServiceName = "messaging-example"
+	config.ExporterType = otelkit.ExporterStdout
+
+	kit, err := otelkit.New(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize OTelKit: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := kit.Shutdown(ctx); err != nil {
+			log.Printf("Failed to shutdown OTelKit: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	orders := &queue{}
+
+	// Producer: every publish injects traceparent into the message headers
+	for i := 1; i <= 5; i++ {
+		headers := otelkit.MessageHeaders{}
+		body := fmt.Sprintf("order-%d", i)
+		err := kit.Publish(ctx, "memory", "orders", headers, func(ctx context.Context) error {
+			orders.Send(message{Body: body, Headers: headers})
+			return nil
+		})
+		if err != nil {
+			log.Printf("Publish failed: %v", err)
+		}
+	}
+
+	// Consumer: one message at a time, each continuing its producer's trace
+	for _, msg := range orders.Receive(2) {
+		err := kit.Consume(ctx, "memory", "orders", msg.Headers, func(ctx context.Context) error {
+			kit.LogInfo(ctx, "Processing order")
+			return nil
+		})
+		if err != nil {
+			log.Printf("Consume failed: %v", err)
+		}
+	}
+
+	// Batch consumer: one span linked to the producer span of every message
+	batch := orders.Receive(10)
+	carriers := make([]propagation.TextMapCarrier, len(batch))
+	for i, msg := range batch {
+		carriers[i] = msg.Headers
+	}
+	err = kit.ConsumeBatch(ctx, "memory", "orders", carriers, func(ctx context.Context) error {
+		kit.LogInfo(ctx, "Processing order batch")
+		return nil
+	})
+	if err != nil {
+		log.Printf("ConsumeBatch failed: %v", err)
+	}
+}
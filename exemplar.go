@@ -0,0 +1,59 @@
+package otelkit
+
+import (
+	"fmt"
+	"net/http"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/sdk/metric/exemplar"
+)
+
+// ExemplarFilter decides which measurements may become exemplars, the sample
+// trace IDs attached to metric points that let dashboards jump from a metric to a trace.
+type ExemplarFilter string
+
+const (
+	// ExemplarFilterTraceBased keeps exemplars only for measurements made in a sampled span (default)
+	ExemplarFilterTraceBased ExemplarFilter = "trace_based"
+
+	// ExemplarFilterAlwaysOn offers every measurement, including ones outside any trace
+	ExemplarFilterAlwaysOn ExemplarFilter = "always_on"
+
+	// ExemplarFilterAlwaysOff disables exemplars
+	ExemplarFilterAlwaysOff ExemplarFilter = "always_off"
+)
+
+// exemplarFilter returns the SDK filter for an ExemplarFilter; "" means trace based.
+func exemplarFilter(filter ExemplarFilter) (exemplar.Filter, error) {
+	switch filter {
+	case "", ExemplarFilterTraceBased:
+		return exemplar.TraceBasedFilter, nil
+	case ExemplarFilterAlwaysOn:
+		return exemplar.AlwaysOnFilter, nil
+	case ExemplarFilterAlwaysOff:
+		return exemplar.AlwaysOffFilter, nil
+	default:
+		return nil, fmt.Errorf("unsupported exemplar filter: %s", filter)
+	}
+}
+
+// MetricsHandler returns an HTTP handler for Prometheus to scrape when
+// MetricsExporterType is ExporterPrometheus (or MetricsExporters includes it).
+//
+// Returns:
+//   - http.Handler: A handler serving the default Prometheus registry
+//
+// The handler negotiates the OpenMetrics format, the only Prometheus format that
+// carries exemplars: histogram buckets then include the trace_id and span_id of
+// a sample request. Prometheus requests it automatically; start it with
+// --enable-feature=exemplar-storage to keep them.
+//
+// Example:
+//   http.Handle("/metrics", kit.MetricsHandler())
+//   go http.ListenAndServe(fmt.Sprintf(":%d", config.PrometheusPort), nil)
+func (o *OTelKit) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(promclient.DefaultGatherer, promhttp.HandlerOpts{
+		EnableOpenMetrics: true,
+	})
+}
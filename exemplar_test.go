@@ -0,0 +1,90 @@
+package otelkit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestExemplars(t *testing.T) {
+	newKit := func(t *testing.T, serviceName string, filter ExemplarFilter) *OTelKit {
+		t.Helper()
+		kit, err := New(Config{
+			ServiceName:         serviceName,
+			ExporterType:        ExporterNone,
+			EnableMetrics:       true,
+			MetricsExporterType: ExporterPrometheus,
+			ExemplarFilter:      filter,
+		})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		t.Cleanup(func() { kit.Shutdown(context.Background()) })
+
+		// Sample every span so the trace-based filter keeps exemplars
+		kit.tracer = sdktrace.NewTracerProvider().Tracer("exemplar-test")
+		return kit
+	}
+
+	// scrape fetches the metrics of serviceName in the OpenMetrics format.
+	scrape := func(t *testing.T, kit *OTelKit, serviceName string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		req.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+		rec := httptest.NewRecorder()
+		kit.MetricsHandler().ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "application/openmetrics-text") {
+			t.Fatalf("Expected an OpenMetrics response, got %d %s", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		var lines []string
+		for _, line := range strings.Split(rec.Body.String(), "\n") {
+			if strings.HasPrefix(line, "http_request_duration_seconds_bucket") && strings.Contains(line, serviceName) {
+				lines = append(lines, line)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	t.Run("TraceBased", func(t *testing.T) {
+		kit := newKit(t, "exemplar-trace-based", "")
+		ctx, span := kit.StartSpan(context.Background(), "request")
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 30*time.Millisecond)
+		kit.RecordHTTPMetrics(context.Background(), "GET", "200", 3*time.Second)
+		span.End()
+
+		buckets := scrape(t, kit, "exemplar-trace-based")
+		traceID := span.SpanContext().TraceID().String()
+		if strings.Count(buckets, `trace_id="`) != 1 || !strings.Contains(buckets, `le="0.05"`) {
+			t.Fatalf("Expected one exemplar in the 0.05 bucket, got:\n%s", buckets)
+		}
+		for _, line := range strings.Split(buckets, "\n") {
+			if strings.Contains(line, `le="0.05"`) && !strings.Contains(line, traceID) {
+				t.Errorf("Expected the 0.05 bucket to link trace %s, got %s", traceID, line)
+			}
+		}
+	})
+
+	t.Run("AlwaysOff", func(t *testing.T) {
+		kit := newKit(t, "exemplar-always-off", ExemplarFilterAlwaysOff)
+		ctx, span := kit.StartSpan(context.Background(), "request")
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 30*time.Millisecond)
+		span.End()
+
+		if buckets := scrape(t, kit, "exemplar-always-off"); buckets == "" || strings.Contains(buckets, "trace_id") {
+			t.Errorf("Expected buckets without exemplars, got:\n%s", buckets)
+		}
+	})
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		_, err := New(Config{ServiceName: "exemplar-invalid", ExporterType: ExporterNone, EnableMetrics: true, MetricsExporterType: ExporterNone, ExemplarFilter: "sometimes"})
+		if err == nil || !strings.Contains(err.Error(), "unsupported exemplar filter") {
+			t.Errorf("Expected an unsupported exemplar filter error, got %v", err)
+		}
+	})
+}
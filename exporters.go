@@ -0,0 +1,169 @@
+package otelkit
+
+import (
+	"fmt"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExporterConfig configures one of several exporters of a signal
+// (Config.TraceExporters, MetricsExporters or LogsExporters).
+//
+// Fields:
+//   - Type: Where the data is sent (ExporterOTLP, ExporterStdout, ...)
+//   - Endpoint: OTLP endpoint or Jaeger URL; empty uses Config.OTLPEndpoint or Config.JaegerURL
+//   - Synchronous: Traces and logs: export each span or record as it ends
+//     instead of batching (for debugging; blocks the caller)
+//   - ExportInterval: Metrics push exporters: 0 uses Config.MetricExportInterval
+//   - ExportTimeout: Metrics push exporters: 0 uses Config.MetricExportTimeout
+//   - Temporality: Metrics push exporters: empty uses Config.MetricTemporality
+//   - Processor: Traces and logs: overrides the fields of Config.SpanProcessor or
+//     Config.LogProcessor that it sets, for this exporter only
+//
+// Example:
+//   config.MetricsExporters = []otelkit.ExporterConfig{
+//       {Type: otelkit.ExporterPrometheus},
+//       {Type: otelkit.ExporterOTLP, ExportInterval: 5 * time.Second},
+//   }
+//   config.TraceExporters = []otelkit.ExporterConfig{
+//       {Type: otelkit.ExporterOTLP, Processor: otelkit.BatchProcessorConfig{MaxQueueSize: 8192}},
+//   }
+type ExporterConfig struct {
+	Type           ExporterType
+	Endpoint       string
+	Synchronous    bool
+	ExportInterval time.Duration
+	ExportTimeout  time.Duration
+	Temporality    MetricTemporality
+	Processor      BatchProcessorConfig
+}
+
+// exporterConfigs converts a list of exporter types from an environment
+// variable to ExporterConfigs; a single type needs no list and returns nil.
+func exporterConfigs(types []string) []ExporterConfig {
+	if len(types) < 2 {
+		return nil
+	}
+	exporters := make([]ExporterConfig, len(types))
+	for i, t := range types {
+		exporters[i] = ExporterConfig{Type: ExporterType(t)}
+	}
+	return exporters
+}
+
+// signalExporters returns exporters, or the single exporterType when the list is empty.
+func signalExporters(exporters []ExporterConfig, exporterType ExporterType) []ExporterConfig {
+	if len(exporters) > 0 {
+		return exporters
+	}
+	return []ExporterConfig{{Type: exporterType}}
+}
+
+// forExporter returns a copy of config set up for creating a single exporter of
+// any signal with createTraceExporter, createMetricsExporter or createLogsExporter.
+func (config Config) forExporter(exporter ExporterConfig) Config {
+	config.ExporterType = exporter.Type
+	config.MetricsExporterType = exporter.Type
+	config.LogsExporterType = exporter.Type
+	if exporter.Endpoint != "" {
+		config.OTLPEndpoint = exporter.Endpoint
+		config.JaegerURL = exporter.Endpoint
+	}
+	if exporter.ExportInterval > 0 {
+		config.MetricExportInterval = exporter.ExportInterval
+	}
+	if exporter.ExportTimeout > 0 {
+		config.MetricExportTimeout = exporter.ExportTimeout
+	}
+	if exporter.Temporality != "" {
+		config.MetricTemporality = exporter.Temporality
+	}
+	return config
+}
+
+// createSpanProcessors creates a span processor for each trace exporter, tuned
+// by Config.SpanProcessor and the exporter's Processor; an ExporterNone
+// exporter creates none. With a
+// pipeline, the exporters report the otelkit_exporter_* metrics.
+func createSpanProcessors(config Config, pipeline *pipelineMetrics) ([]sdktrace.SpanProcessor, error) {
+	if err := config.SpanProcessor.validate(); err != nil {
+		return nil, fmt.Errorf("span processor: %w", err)
+	}
+
+	var processors []sdktrace.SpanProcessor
+	exporters := signalExporters(config.TraceExporters, config.ExporterType)
+	for i, e := range exporters {
+		if err := e.Processor.validate(); err != nil {
+			return nil, fmt.Errorf("trace exporter %s: span processor: %w", e.Type, err)
+		}
+		stats := pipeline.exporter(signalTraces, exporterName(exporters, i))
+		exporter, err := createTraceExporter(config.forExporter(e), stats)
+		if err != nil {
+			return nil, fmt.Errorf("trace exporter %s: %w", e.Type, err)
+		}
+		if exporter == nil {
+			continue
+		}
+		processors = append(processors, newSpanProcessor(exporter, config.SpanProcessor.overriddenBy(e.Processor), e.Synchronous, stats))
+	}
+	return processors, nil
+}
+
+// createMetricReaders creates a metric reader for each metrics exporter; an
+// ExporterNone exporter creates none. Prometheus can only be used once, as it
+// registers with the default Prometheus registry. With a pipeline, the push
+// exporters report the otelkit_exporter_* metrics; producers add to what every
+// reader collects.
+func createMetricReaders(config Config, pipeline *pipelineMetrics, producers ...sdkmetric.Producer) ([]sdkmetric.Reader, error) {
+	var readers []sdkmetric.Reader
+	prometheusReaders := 0
+	exporters := signalExporters(config.MetricsExporters, config.MetricsExporterType)
+	for i, e := range exporters {
+		if e.Type == ExporterPrometheus {
+			if prometheusReaders++; prometheusReaders > 1 {
+				return nil, fmt.Errorf("metrics exporter %s can only be configured once", e.Type)
+			}
+		}
+
+		stats := pipeline.exporter(signalMetrics, exporterName(exporters, i))
+		reader, err := createMetricsExporter(config.forExporter(e), stats, producers...)
+		if err != nil {
+			return nil, fmt.Errorf("metrics exporter %s: %w", e.Type, err)
+		}
+		if reader != nil {
+			readers = append(readers, reader)
+		}
+	}
+	return readers, nil
+}
+
+// createLogProcessors creates a log processor for each logs exporter, tuned by
+// Config.LogProcessor and the exporter's Processor; an ExporterNone exporter
+// creates none. With a pipeline,
+// the exporters report the otelkit_exporter_* metrics.
+func createLogProcessors(config Config, pipeline *pipelineMetrics) ([]sdklog.Processor, error) {
+	if err := config.LogProcessor.validate(); err != nil {
+		return nil, fmt.Errorf("log processor: %w", err)
+	}
+
+	var processors []sdklog.Processor
+	exporters := signalExporters(config.LogsExporters, config.LogsExporterType)
+	for i, e := range exporters {
+		if err := e.Processor.validate(); err != nil {
+			return nil, fmt.Errorf("logs exporter %s: log processor: %w", e.Type, err)
+		}
+		stats := pipeline.exporter(signalLogs, exporterName(exporters, i))
+		exporter, err := createLogsExporter(config.forExporter(e), stats)
+		if err != nil {
+			return nil, fmt.Errorf("logs exporter %s: %w", e.Type, err)
+		}
+		if exporter == nil {
+			continue
+		}
+		processors = append(processors, newLogProcessor(exporter, config.LogProcessor.overriddenBy(e.Processor), e.Synchronous, stats))
+	}
+	return processors, nil
+}
@@ -0,0 +1,136 @@
+package otelkit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	promclient "github.com/prometheus/client_golang/prometheus"
+)
+
+// otlpStub is an OTLP/HTTP endpoint counting the export requests per path.
+type otlpStub struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newOTLPStub(t *testing.T) *otlpStub {
+	t.Helper()
+	stub := &otlpStub{requests: make(map[string]int)}
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stub.mu.Lock()
+		stub.requests[r.URL.Path]++
+		stub.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+// endpoint returns the host:port the OTLP exporters expect.
+func (s *otlpStub) endpoint() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func (s *otlpStub) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+func TestMultipleExporters(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TracesAndMetrics", func(t *testing.T) {
+		primary, debug := newOTLPStub(t), newOTLPStub(t)
+
+		kit, err := New(Config{
+			ServiceName:   "multi-exporter",
+			SampleRate:    1,
+			EnableMetrics: true,
+			TraceExporters: []ExporterConfig{
+				{Type: ExporterOTLP, Endpoint: primary.endpoint()},
+				{Type: ExporterOTLP, Endpoint: debug.endpoint(), Synchronous: true},
+			},
+			MetricsExporters: []ExporterConfig{
+				{Type: ExporterPrometheus},
+				{Type: ExporterOTLP, Endpoint: primary.endpoint()},
+			},
+		})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+
+		_, span := kit.StartSpan(ctx, "fan-out")
+		span.End()
+		if debug.count("/v1/traces") != 1 {
+			t.Errorf("Expected the synchronous exporter to export on span end, got %d requests", debug.count("/v1/traces"))
+		}
+		kit.RecordMetric(ctx, "fan_out", 1)
+
+		families, err := promclient.DefaultGatherer.Gather()
+		if err != nil {
+			t.Fatalf("Failed to gather: %v", err)
+		}
+		scraped := false
+		for _, family := range families {
+			scraped = scraped || family.GetName() == "otelkit_business_operations_total"
+		}
+		if !scraped {
+			t.Error("Expected the Prometheus reader to serve otelkit_business_operations_total")
+		}
+
+		if err := kit.Shutdown(ctx); err != nil {
+			t.Fatalf("Failed to shut down: %v", err)
+		}
+		if primary.count("/v1/traces") != 1 {
+			t.Errorf("Expected the batched exporter to export at shutdown, got %d requests", primary.count("/v1/traces"))
+		}
+		if primary.count("/v1/metrics") == 0 {
+			t.Error("Expected the OTLP reader to push metrics at shutdown")
+		}
+	})
+
+	t.Run("Logs", func(t *testing.T) {
+		processors, err := createLogProcessors(Config{LogsExporters: []ExporterConfig{
+			{Type: ExporterOTLP, Endpoint: "localhost:4318"},
+			{Type: ExporterStdout, Synchronous: true},
+			{Type: ExporterNone},
+		}}, nil)
+		if err != nil {
+			t.Fatalf("Failed to create log processors: %v", err)
+		}
+		if len(processors) != 2 {
+			t.Errorf("Expected 2 log processors, got %d", len(processors))
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := createMetricReaders(Config{MetricsExporters: []ExporterConfig{{Type: ExporterPrometheus}, {Type: ExporterPrometheus}}}, nil)
+		if err == nil {
+			t.Error("Expected an error for two Prometheus exporters")
+		}
+
+		_, err = createSpanProcessors(Config{TraceExporters: []ExporterConfig{{Type: ExporterStdout}, {Type: "zipkin"}}}, nil)
+		if err == nil || !strings.Contains(err.Error(), "zipkin") {
+			t.Errorf("Expected an error naming the unsupported exporter, got %v", err)
+		}
+	})
+
+	t.Run("Env", func(t *testing.T) {
+		t.Setenv("OTEL_METRICS_EXPORTER", "prometheus, otlp")
+		t.Setenv("OTEL_EXPORTER_TYPE", "otlp")
+
+		config := DefaultConfig()
+		if len(config.MetricsExporters) != 2 || config.MetricsExporters[1].Type != ExporterOTLP {
+			t.Errorf("Expected prometheus and otlp metrics exporters, got %+v", config.MetricsExporters)
+		}
+		if config.TraceExporters != nil || config.ExporterType != ExporterOTLP {
+			t.Errorf("Expected a single otlp trace exporter, got %q and %+v", config.ExporterType, config.TraceExporters)
+		}
+	})
+}
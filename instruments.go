@@ -0,0 +1,467 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+)
+
+// maxInstrumentNameLength is the longest instrument name OpenTelemetry allows.
+const maxInstrumentNameLength = 255
+
+// maxInstrumentUnitLength is the longest instrument unit OpenTelemetry allows.
+const maxInstrumentUnitLength = 63
+
+// maxInstrumentDescriptionLength is the longest instrument description OpenTelemetry allows.
+const maxInstrumentDescriptionLength = 1023
+
+// MetricOption configures a custom instrument (Counter, Histogram, Gauge, ...).
+type MetricOption func(*metricConfig)
+
+// metricConfig collects the settings applied by MetricOption values.
+//
+// Fields:
+//   - description: Human-readable description of the metric
+//   - unit: UCUM unit of the recorded values (e.g. "s", "By", "{request}")
+//   - cardinalityLimit: Series limit of the instrument (0 = Config.MetricCardinalityLimit)
+type metricConfig struct {
+	description      string
+	unit             string
+	cardinalityLimit int
+}
+
+// WithMetricDescription sets the description shown as the metric's HELP text.
+func WithMetricDescription(description string) MetricOption {
+	return func(c *metricConfig) {
+		c.description = description
+	}
+}
+
+// WithMetricUnit sets the UCUM unit of the recorded values (e.g. "s", "ms", "By", "{order}").
+func WithMetricUnit(unit string) MetricOption {
+	return func(c *metricConfig) {
+		c.unit = unit
+	}
+}
+
+// WithCardinalityLimit caps the distinct attribute sets of the instrument, overriding
+// Config.MetricCardinalityLimit; a negative limit disables it. Measurements with new
+// attribute sets beyond the limit are recorded under otel.metric.overflow=true.
+// An entry for the instrument in Config.MetricCardinalityLimits takes precedence,
+// and observable instruments are not limited.
+func WithCardinalityLimit(limit int) MetricOption {
+	return func(c *metricConfig) {
+		c.cardinalityLimit = limit
+	}
+}
+
+// instrumentKind names the instrument types of the custom instrument API.
+type instrumentKind string
+
+const (
+	kindCounter                 instrumentKind = "counter"
+	kindUpDownCounter           instrumentKind = "up-down counter"
+	kindHistogram               instrumentKind = "histogram"
+	kindGauge                   instrumentKind = "gauge"
+	kindObservableCounter       instrumentKind = "observable counter"
+	kindObservableUpDownCounter instrumentKind = "observable up-down counter"
+	kindObservableGauge         instrumentKind = "observable gauge"
+)
+
+// instrumentEntry is a cached custom instrument.
+//
+// Fields:
+//   - kind: The instrument type
+//   - config: The description and unit it was created with
+//   - buckets: Histogram bucket boundaries it was created with
+//   - instrument: The wrapper (*Counter, *Histogram, ...) or observable instrument
+type instrumentEntry struct {
+	kind       instrumentKind
+	config     metricConfig
+	buckets    []float64
+	instrument any
+}
+
+// Counter is a monotonic counter created with OTelKit.Counter.
+type Counter struct {
+	counter metric.Int64Counter
+}
+
+// Add increments the counter by n (n must not be negative).
+func (c *Counter) Add(ctx context.Context, n int64, attrs ...attribute.KeyValue) {
+	c.counter.Add(ctx, n, metric.WithAttributes(attrs...))
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc(ctx context.Context, attrs ...attribute.KeyValue) {
+	c.counter.Add(ctx, 1, metric.WithAttributes(attrs...))
+}
+
+// UpDownCounter is a counter that can go down, created with OTelKit.UpDownCounter.
+// Use it for quantities such as queue depth or in-flight requests.
+type UpDownCounter struct {
+	counter metric.Int64UpDownCounter
+}
+
+// Add adds n (which may be negative) to the counter.
+func (c *UpDownCounter) Add(ctx context.Context, n int64, attrs ...attribute.KeyValue) {
+	c.counter.Add(ctx, n, metric.WithAttributes(attrs...))
+}
+
+// Histogram records a distribution of values, created with OTelKit.Histogram.
+type Histogram struct {
+	histogram metric.Float64Histogram
+}
+
+// Record adds one value to the distribution.
+func (h *Histogram) Record(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	h.histogram.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
+// RecordDuration records d in seconds, for histograms with unit "s".
+func (h *Histogram) RecordDuration(ctx context.Context, d time.Duration, attrs ...attribute.KeyValue) {
+	h.histogram.Record(ctx, d.Seconds(), metric.WithAttributes(attrs...))
+}
+
+// Gauge records the current value of something, created with OTelKit.Gauge.
+type Gauge struct {
+	gauge metric.Float64Gauge
+}
+
+// Set records value as the current value.
+func (g *Gauge) Set(ctx context.Context, value float64, attrs ...attribute.KeyValue) {
+	g.gauge.Record(ctx, value, metric.WithAttributes(attrs...))
+}
+
+// ObserveFunc reports the current values of an observable instrument. It is
+// called on every metrics collection; call observe once per attribute set.
+type ObserveFunc func(ctx context.Context, observe func(value float64, attrs ...attribute.KeyValue)) error
+
+// Counter returns the monotonic counter with the given name, creating it on first use.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - *Counter: The counter; safe for concurrent use
+//   - error: The name, unit or description is invalid, or the name is already
+//     used by another instrument type or with different options
+//
+// Instruments are cached by name, so calling Counter again with the same name
+// and options returns the same counter; there is no need to keep it in a global.
+// When metrics are disabled a no-op counter is returned.
+//
+// Example:
+//   orders, err := kit.Counter("orders_placed_total", otelkit.WithMetricDescription("Orders placed"))
+//   if err != nil {
+//       return err
+//   }
+//   orders.Inc(ctx, attribute.String("region", "us-east"))
+func (o *OTelKit) Counter(name string, opts ...MetricOption) (*Counter, error) {
+	entry, err := o.instrument(name, kindCounter, nil, opts, func(meter metric.Meter, name string, cfg metricConfig) (any, error) {
+		counter, err := meter.Int64Counter(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+		return &Counter{counter: counter}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.(*Counter), nil
+}
+
+// UpDownCounter returns the up-down counter with the given name, creating it on first use.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - *UpDownCounter: The counter; safe for concurrent use
+//   - error: As for Counter
+//
+// Example:
+//   inFlight, _ := kit.UpDownCounter("jobs_in_flight")
+//   inFlight.Add(ctx, 1)
+//   defer inFlight.Add(ctx, -1)
+func (o *OTelKit) UpDownCounter(name string, opts ...MetricOption) (*UpDownCounter, error) {
+	entry, err := o.instrument(name, kindUpDownCounter, nil, opts, func(meter metric.Meter, name string, cfg metricConfig) (any, error) {
+		counter, err := meter.Int64UpDownCounter(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+		return &UpDownCounter{counter: counter}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.(*UpDownCounter), nil
+}
+
+// Histogram returns the histogram with the given name, creating it on first use.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - buckets: Explicit bucket boundaries in increasing order; nil uses the SDK defaults
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - *Histogram: The histogram; safe for concurrent use
+//   - error: As for Counter, or the buckets are not finite and strictly increasing
+//
+// Each bucket keeps one exemplar (see ExemplarFilter).
+//
+// Example:
+//   latency, _ := kit.Histogram("payment_latency_seconds", []float64{0.1, 0.5, 1, 5},
+//       otelkit.WithMetricUnit("s"))
+//   latency.RecordDuration(ctx, time.Since(start), attribute.String("provider", "stripe"))
+func (o *OTelKit) Histogram(name string, buckets []float64, opts ...MetricOption) (*Histogram, error) {
+	if err := validateBuckets(buckets); err != nil {
+		return nil, fmt.Errorf("histogram %q: %w", name, err)
+	}
+
+	entry, err := o.instrument(name, kindHistogram, buckets, opts, func(meter metric.Meter, name string, cfg metricConfig) (any, error) {
+		histOpts := []metric.Float64HistogramOption{metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit)}
+		if len(buckets) > 0 {
+			histOpts = append(histOpts, metric.WithExplicitBucketBoundaries(buckets...))
+		}
+		histogram, err := meter.Float64Histogram(name, histOpts...)
+		return &Histogram{histogram: histogram}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.(*Histogram), nil
+}
+
+// Gauge returns the synchronous gauge with the given name, creating it on first use.
+// Use it to record a current value when it changes; prefer ObservableGauge for
+// values that are cheaper to read at collection time.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - *Gauge: The gauge; safe for concurrent use
+//   - error: As for Counter
+//
+// Example:
+//   temperature, _ := kit.Gauge("room_temperature", otelkit.WithMetricUnit("Cel"))
+//   temperature.Set(ctx, 21.5, attribute.String("room", "lab"))
+func (o *OTelKit) Gauge(name string, opts ...MetricOption) (*Gauge, error) {
+	entry, err := o.instrument(name, kindGauge, nil, opts, func(meter metric.Meter, name string, cfg metricConfig) (any, error) {
+		gauge, err := meter.Float64Gauge(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+		return &Gauge{gauge: gauge}, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entry.(*Gauge), nil
+}
+
+// ObservableGauge registers fn to report the current value of a gauge at every collection.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - fn: Reports the values; must be safe for concurrent use
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - metric.Registration: Unregister it to stop calling fn
+//   - error: As for Counter, or the callback could not be registered
+//
+// Several callbacks may be registered for the same name; each reports its own
+// attribute sets.
+//
+// Example:
+//   reg, err := kit.ObservableGauge("queue_depth", func(ctx context.Context, observe func(float64, ...attribute.KeyValue)) error {
+//       observe(float64(queue.Len()), attribute.String("queue", "emails"))
+//       return nil
+//   })
+//   defer reg.Unregister()
+func (o *OTelKit) ObservableGauge(name string, fn ObserveFunc, opts ...MetricOption) (metric.Registration, error) {
+	return o.observable(name, kindObservableGauge, fn, opts, func(meter metric.Meter, name string, cfg metricConfig) (metric.Float64Observable, error) {
+		return meter.Float64ObservableGauge(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+	})
+}
+
+// ObservableCounter registers fn to report the running total of a monotonic
+// counter (e.g. bytes read from a connection's own stats) at every collection.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - fn: Reports the cumulative totals; must be safe for concurrent use
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - metric.Registration: Unregister it to stop calling fn
+//   - error: As for ObservableGauge
+func (o *OTelKit) ObservableCounter(name string, fn ObserveFunc, opts ...MetricOption) (metric.Registration, error) {
+	return o.observable(name, kindObservableCounter, fn, opts, func(meter metric.Meter, name string, cfg metricConfig) (metric.Float64Observable, error) {
+		return meter.Float64ObservableCounter(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+	})
+}
+
+// ObservableUpDownCounter registers fn to report the current total of an
+// up-down counter (e.g. open connections of a pool) at every collection.
+//
+// Parameters:
+//   - name: Metric name; normalized with NormalizeMetricName
+//   - fn: Reports the current totals; must be safe for concurrent use
+//   - opts: Optional settings (WithMetricDescription, WithMetricUnit)
+//
+// Returns:
+//   - metric.Registration: Unregister it to stop calling fn
+//   - error: As for ObservableGauge
+func (o *OTelKit) ObservableUpDownCounter(name string, fn ObserveFunc, opts ...MetricOption) (metric.Registration, error) {
+	return o.observable(name, kindObservableUpDownCounter, fn, opts, func(meter metric.Meter, name string, cfg metricConfig) (metric.Float64Observable, error) {
+		return meter.Float64ObservableUpDownCounter(name, metric.WithDescription(cfg.description), metric.WithUnit(cfg.unit))
+	})
+}
+
+// observable gets or creates an observable instrument and registers fn as its callback.
+func (o *OTelKit) observable(name string, kind instrumentKind, fn ObserveFunc, opts []MetricOption, create func(metric.Meter, string, metricConfig) (metric.Float64Observable, error)) (metric.Registration, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("%s %q: nil callback", kind, name)
+	}
+
+	entry, err := o.instrument(name, kind, nil, opts, func(meter metric.Meter, name string, cfg metricConfig) (any, error) {
+		return create(meter, name, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	instrument := entry.(metric.Float64Observable)
+
+	return o.instrumentMeter().RegisterCallback(func(ctx context.Context, observer metric.Observer) error {
+		return fn(ctx, func(value float64, attrs ...attribute.KeyValue) {
+			observer.ObserveFloat64(instrument, value, metric.WithAttributes(attrs...))
+		})
+	}, instrument)
+}
+
+// instrument returns the cached instrument for name, or creates and caches it.
+// A name already cached with another kind, unit, description or buckets is an error.
+func (o *OTelKit) instrument(name string, kind instrumentKind, buckets []float64, opts []MetricOption, create func(metric.Meter, string, metricConfig) (any, error)) (any, error) {
+	normalized, err := NormalizeMetricName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg metricConfig
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	if err := validateMetricConfig(cfg); err != nil {
+		return nil, fmt.Errorf("%s %q: %w", kind, normalized, err)
+	}
+
+	o.instrumentsMu.Lock()
+	defer o.instrumentsMu.Unlock()
+
+	if entry, ok := o.instruments[normalized]; ok {
+		switch {
+		case entry.kind != kind:
+			return nil, fmt.Errorf("metric %q is already registered as a %s", normalized, entry.kind)
+		case entry.config != cfg || !slices.Equal(entry.buckets, buckets):
+			return nil, fmt.Errorf("%s %q is already registered with a different unit, description, cardinality limit or buckets", kind, normalized)
+		}
+		return entry.instrument, nil
+	}
+
+	if cfg.cardinalityLimit != 0 && o.cardinality != nil {
+		o.cardinality.setLimit(normalized, cfg.cardinalityLimit)
+	}
+
+	instrument, err := create(o.instrumentMeter(), normalized, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s %q: %w", kind, normalized, err)
+	}
+
+	if o.instruments == nil {
+		o.instruments = make(map[string]*instrumentEntry)
+	}
+	o.instruments[normalized] = &instrumentEntry{kind: kind, config: cfg, buckets: slices.Clone(buckets), instrument: instrument}
+	return instrument, nil
+}
+
+// instrumentMeter returns the meter custom instruments are created with: the
+// kit's meter, or a no-op meter when metrics are disabled.
+func (o *OTelKit) instrumentMeter() metric.Meter {
+	if o.meter == nil {
+		return noop.NewMeterProvider().Meter("otelkit")
+	}
+	return o.meter
+}
+
+// NormalizeMetricName converts name to a valid OpenTelemetry instrument name:
+// it is trimmed and lowercased, and every run of characters other than letters,
+// digits, '_', '.', '-' and '/' becomes a single '_'.
+//
+// Parameters:
+//   - name: The requested metric name (e.g. "Orders Placed Total")
+//
+// Returns:
+//   - string: The normalized name (e.g. "orders_placed_total")
+//   - error: The normalized name is empty, does not start with a letter or is
+//     longer than 255 characters
+func NormalizeMetricName(name string) (string, error) {
+	var b strings.Builder
+	replaced := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '_', r == '.', r == '-', r == '/':
+			b.WriteRune(r)
+			replaced = false
+		case !replaced:
+			b.WriteByte('_')
+			replaced = true
+		}
+	}
+
+	normalized := strings.Trim(b.String(), "_")
+	switch {
+	case normalized == "":
+		return "", fmt.Errorf("invalid metric name %q: empty", name)
+	case normalized[0] < 'a' || normalized[0] > 'z':
+		return "", fmt.Errorf("invalid metric name %q: must start with a letter", name)
+	case len(normalized) > maxInstrumentNameLength:
+		return "", fmt.Errorf("invalid metric name %q: longer than %d characters", name, maxInstrumentNameLength)
+	}
+	return normalized, nil
+}
+
+// validateMetricConfig checks the unit and description against the OpenTelemetry limits.
+func validateMetricConfig(cfg metricConfig) error {
+	if len(cfg.unit) > maxInstrumentUnitLength {
+		return fmt.Errorf("unit %q is longer than %d characters", cfg.unit, maxInstrumentUnitLength)
+	}
+	for _, r := range cfg.unit {
+		if r <= ' ' || r > '~' {
+			return fmt.Errorf("unit %q must be printable ASCII without spaces", cfg.unit)
+		}
+	}
+	if len(cfg.description) > maxInstrumentDescriptionLength {
+		return fmt.Errorf("description is longer than %d characters", maxInstrumentDescriptionLength)
+	}
+	return nil
+}
+
+// validateBuckets checks that histogram bucket boundaries are finite and strictly increasing.
+func validateBuckets(buckets []float64) error {
+	for i, bound := range buckets {
+		if math.IsNaN(bound) || math.IsInf(bound, 0) {
+			return fmt.Errorf("bucket boundary %v is not finite", bound)
+		}
+		if i > 0 && bound <= buckets[i-1] {
+			return fmt.Errorf("bucket boundaries must be strictly increasing, got %v after %v", bound, buckets[i-1])
+		}
+	}
+	return nil
+}
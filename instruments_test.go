@@ -0,0 +1,200 @@
+package otelkit
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newInstrumentKit returns a kit whose custom instruments are collected by the returned reader.
+func newInstrumentKit() (*OTelKit, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	kit := &OTelKit{meter: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("instruments-test")}
+	return kit, reader
+}
+
+// findMetric returns the collected metric with the given name or fails the test.
+func findMetric(t *testing.T, reader *sdkmetric.ManualReader, name string) metricdata.Metrics {
+	t.Helper()
+	for _, m := range collectMetrics(t, reader) {
+		if m.Name == name {
+			return m
+		}
+	}
+	t.Fatalf("Metric %s not found", name)
+	return metricdata.Metrics{}
+}
+
+func TestCustomInstruments(t *testing.T) {
+	ctx := context.Background()
+	kit, reader := newInstrumentKit()
+
+	t.Run("Counter", func(t *testing.T) {
+		orders, err := kit.Counter("Orders Placed Total", WithMetricDescription("Orders placed"), WithMetricUnit("{order}"))
+		if err != nil {
+			t.Fatalf("Counter failed: %v", err)
+		}
+		orders.Inc(ctx, attribute.String("region", "eu"))
+		orders.Add(ctx, 2, attribute.String("region", "eu"))
+
+		m := findMetric(t, reader, "orders_placed_total")
+		if m.Description != "Orders placed" || m.Unit != "{order}" {
+			t.Errorf("Unexpected description/unit: %q %q", m.Description, m.Unit)
+		}
+		if dp := m.Data.(metricdata.Sum[int64]).DataPoints; len(dp) != 1 || dp[0].Value != 3 {
+			t.Errorf("Expected 3 orders, got %+v", dp)
+		}
+	})
+
+	t.Run("Histogram", func(t *testing.T) {
+		latency, err := kit.Histogram("payment_latency_seconds", []float64{0.1, 1}, WithMetricUnit("s"))
+		if err != nil {
+			t.Fatalf("Histogram failed: %v", err)
+		}
+		latency.RecordDuration(ctx, 500*time.Millisecond)
+		latency.Record(ctx, 2)
+
+		dp := findMetric(t, reader, "payment_latency_seconds").Data.(metricdata.Histogram[float64]).DataPoints
+		if len(dp) != 1 || len(dp[0].Bounds) != 2 || dp[0].BucketCounts[1] != 1 || dp[0].BucketCounts[2] != 1 {
+			t.Errorf("Unexpected histogram: %+v", dp)
+		}
+	})
+
+	t.Run("GaugeAndUpDownCounter", func(t *testing.T) {
+		temperature, err := kit.Gauge("room_temperature", WithMetricUnit("Cel"))
+		if err != nil {
+			t.Fatalf("Gauge failed: %v", err)
+		}
+		temperature.Set(ctx, 20)
+		temperature.Set(ctx, 21.5)
+
+		inFlight, err := kit.UpDownCounter("jobs_in_flight")
+		if err != nil {
+			t.Fatalf("UpDownCounter failed: %v", err)
+		}
+		inFlight.Add(ctx, 3)
+		inFlight.Add(ctx, -1)
+
+		if dp := findMetric(t, reader, "room_temperature").Data.(metricdata.Gauge[float64]).DataPoints; dp[0].Value != 21.5 {
+			t.Errorf("Expected 21.5, got %v", dp[0].Value)
+		}
+		if dp := findMetric(t, reader, "jobs_in_flight").Data.(metricdata.Sum[int64]).DataPoints; dp[0].Value != 2 {
+			t.Errorf("Expected 2, got %v", dp[0].Value)
+		}
+	})
+
+	t.Run("Observable", func(t *testing.T) {
+		depth := 7.0
+		reg, err := kit.ObservableGauge("queue_depth", func(ctx context.Context, observe func(float64, ...attribute.KeyValue)) error {
+			observe(depth, attribute.String("queue", "emails"))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ObservableGauge failed: %v", err)
+		}
+		if dp := findMetric(t, reader, "queue_depth").Data.(metricdata.Gauge[float64]).DataPoints; len(dp) != 1 || dp[0].Value != 7 {
+			t.Errorf("Expected depth 7, got %+v", dp)
+		}
+
+		reg.Unregister()
+		for _, m := range collectMetrics(t, reader) {
+			if m.Name == "queue_depth" && len(m.Data.(metricdata.Gauge[float64]).DataPoints) > 0 {
+				t.Error("Expected no observations after Unregister")
+			}
+		}
+
+		if _, err := kit.ObservableCounter("bytes_read_total", nil); err == nil {
+			t.Error("Expected an error for a nil callback")
+		}
+	})
+
+	t.Run("Cache", func(t *testing.T) {
+		first, _ := kit.Counter("cached_total")
+		second, _ := kit.Counter(" Cached Total ")
+		if first != second {
+			t.Error("Expected the cached counter to be returned")
+		}
+
+		if _, err := kit.Histogram("cached_total", nil); err == nil || !strings.Contains(err.Error(), "already registered as a counter") {
+			t.Errorf("Expected a kind conflict, got %v", err)
+		}
+		if _, err := kit.Counter("cached_total", WithMetricUnit("By")); err == nil {
+			t.Error("Expected an options conflict")
+		}
+	})
+
+	t.Run("ConcurrentCreation", func(t *testing.T) {
+		var wg sync.WaitGroup
+		counters := make([]*Counter, 20)
+		for i := range counters {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				counters[i], _ = kit.Counter("concurrent_total")
+				counters[i].Inc(ctx)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, c := range counters {
+			if c != counters[0] {
+				t.Fatal("Expected every goroutine to get the same counter")
+			}
+		}
+		if dp := findMetric(t, reader, "concurrent_total").Data.(metricdata.Sum[int64]).DataPoints; dp[0].Value != 20 {
+			t.Errorf("Expected 20, got %v", dp[0].Value)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		if _, err := kit.Counter("requests", WithMetricUnit("per second")); err == nil {
+			t.Error("Expected an invalid unit error")
+		}
+		if _, err := kit.Counter("requests", WithMetricDescription(strings.Repeat("x", 2000))); err == nil {
+			t.Error("Expected a description length error")
+		}
+		if _, err := kit.Histogram("sizes", []float64{10, 5}); err == nil {
+			t.Error("Expected an unsorted buckets error")
+		}
+	})
+
+	t.Run("MetricsDisabled", func(t *testing.T) {
+		disabled := &OTelKit{}
+		counter, err := disabled.Counter("noop_total")
+		if err != nil {
+			t.Fatalf("Counter failed: %v", err)
+		}
+		counter.Inc(ctx)
+	})
+}
+
+func TestNormalizeMetricName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"orders_total", "orders_total", false},
+		{"Orders Placed", "orders_placed", false},
+		{"  http.server.duration ", "http.server.duration", false},
+		{"cache hit-ratio (%)", "cache_hit-ratio", false},
+		{"bytes/sec", "bytes/sec", false},
+		{"", "", true},
+		{"!!!", "", true},
+		{"2xx_responses", "", true},
+		{strings.Repeat("a", 300), "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeMetricName(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("NormalizeMetricName(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
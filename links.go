@@ -0,0 +1,185 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// defaultMaxSpanLinks is used when Config.MaxSpanLinks is 0.
+const defaultMaxSpanLinks = 128
+
+// maxBatchItemEvents caps the per-item events BatchOperationWithLinks adds to
+// its span. It stays one under the SDK's default event limit of 128, leaving
+// room for the exception event recorded when the batch fails, so the SDK never
+// evicts events without a trace.
+const maxBatchItemEvents = 127
+
+// BatchItem identifies one item of a batch and the trace it came from.
+// Set SpanContext directly, or Carrier to extract it from propagated headers.
+//
+// Fields:
+//   - ID: Item identifier recorded on the link and item events (optional)
+//   - SpanContext: The span that produced the item
+//   - Carrier: Headers to extract the span context from when SpanContext is not valid
+//   - Attributes: Extra attributes recorded on the link
+type BatchItem struct {
+	ID          string
+	SpanContext trace.SpanContext
+	Carrier     propagation.TextMapCarrier
+	Attributes  []attribute.KeyValue
+}
+
+// label identifies the item in events and errors: its ID, or its index.
+func (item BatchItem) label(index int) string {
+	if item.ID != "" {
+		return item.ID
+	}
+	return strconv.Itoa(index)
+}
+
+// batchLinks builds one link per item with a valid span context, up to the
+// configured cap.
+//
+// Returns:
+//   - []trace.Link: The links, carrying batch.item.index and batch.item.id attributes
+//   - int: Number of valid links dropped because of the cap
+func (o *OTelKit) batchLinks(items []BatchItem) ([]trace.Link, int) {
+	limit := o.config.MaxSpanLinks
+	if limit <= 0 {
+		limit = defaultMaxSpanLinks
+	}
+
+	var links []trace.Link
+	dropped := 0
+	for i, item := range items {
+		sc := item.SpanContext
+		if !sc.IsValid() && item.Carrier != nil {
+			sc = trace.SpanContextFromContext(o.textMapPropagator().Extract(context.Background(), item.Carrier))
+		}
+		if !sc.IsValid() {
+			continue
+		}
+		if len(links) == limit {
+			dropped++
+			continue
+		}
+
+		attrs := append([]attribute.KeyValue{attribute.Int("batch.item.index", i)}, item.Attributes...)
+		if item.ID != "" {
+			attrs = append(attrs, attribute.String("batch.item.id", item.ID))
+		}
+		links = append(links, trace.Link{SpanContext: sc, Attributes: attrs})
+	}
+	return links, dropped
+}
+
+// StartSpanWithLinks starts a span linked to the trace of every item, for work
+// that fans in items from many traces (batch jobs, aggregations).
+//
+// Parameters:
+//   - ctx: Parent context (may contain parent span)
+//   - spanName: Descriptive name for the span
+//   - items: The items whose span contexts are linked
+//   - opts: Optional span start options
+//
+// Returns:
+//   - context.Context: New context containing the span
+//   - trace.Span: The created span (must call span.End() when operation completes)
+//
+// At most Config.MaxSpanLinks links are attached; when items exceed the cap the
+// number of links left out is recorded as batch.links_dropped.
+//
+// Example:
+//   items := make([]otelkit.BatchItem, len(jobs))
+//   for i, job := range jobs {
+//       items[i] = otelkit.BatchItem{ID: job.ID, Carrier: job.Headers}
+//   }
+//   ctx, span := kit.StartSpanWithLinks(ctx, "reconcile", items)
+//   defer span.End()
+func (o *OTelKit) StartSpanWithLinks(ctx context.Context, spanName string, items []BatchItem, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	links, dropped := o.batchLinks(items)
+	opts = append([]trace.SpanStartOption{trace.WithLinks(links...)}, opts...)
+	if dropped > 0 {
+		opts = append(opts, trace.WithAttributes(attribute.Int("batch.links_dropped", dropped)))
+	}
+	return o.StartSpan(ctx, spanName, opts...)
+}
+
+// BatchOperationWithLinks traces a batch whose items come from other traces,
+// calling fn once per item and recording each item's outcome.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - operationName: Name of the batch operation (e.g., "process_orders")
+//   - items: The items to process; their span contexts are linked as in StartSpanWithLinks
+//   - fn: Processes one item; receives the batch span context
+//   - opts: Optional span settings (kind, links, attributes, start time)
+//
+// Returns:
+//   - error: The failures of all items joined with errors.Join, each prefixed with
+//     the item ID (or index); nil when every item succeeded
+//
+// The span "batch.{operationName}" has the attributes of BatchOperation plus:
+//   - batch.failed_count: Number of items whose fn returned an error
+//   - batch.links_dropped: Number of links left out by the cap (only when > 0)
+//   - batch.events_dropped: Number of item events left out by the cap (only when > 0)
+//
+// Each item adds a batch.item.succeeded or batch.item.failed event with
+// batch.item.index, batch.item.id and, for failures, error.message. Only the
+// first 127 item events are recorded; batch.failed_count still covers every item.
+// Every item is processed even after a failure.
+//
+// Example:
+//   err := kit.BatchOperationWithLinks(ctx, "send_emails", items, func(ctx context.Context, item otelkit.BatchItem) error {
+//       return mailer.Send(ctx, emails[item.ID])
+//   })
+func (o *OTelKit) BatchOperationWithLinks(ctx context.Context, operationName string, items []BatchItem, fn func(ctx context.Context, item BatchItem) error, opts ...TraceOption) error {
+	links, dropped := o.batchLinks(items)
+	attrs := []attribute.KeyValue{
+		attribute.String("batch.operation", operationName),
+		attribute.Int("batch.item_count", len(items)),
+	}
+	if dropped > 0 {
+		attrs = append(attrs, attribute.Int("batch.links_dropped", dropped))
+	}
+
+	return o.traceWithOptions(ctx, "batch."+operationName, func(ctx context.Context) error {
+		span := trace.SpanFromContext(ctx)
+		var errs []error
+		eventsDropped := 0
+
+		for i, item := range items {
+			err := fn(ctx, item)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("batch item %s: %w", item.label(i), err))
+			}
+			if i >= maxBatchItemEvents {
+				eventsDropped++
+				continue
+			}
+
+			eventAttrs := []attribute.KeyValue{attribute.Int("batch.item.index", i)}
+			if item.ID != "" {
+				eventAttrs = append(eventAttrs, attribute.String("batch.item.id", item.ID))
+			}
+
+			if err != nil {
+				span.AddEvent("batch.item.failed", trace.WithAttributes(append(eventAttrs, attribute.String("error.message", err.Error()))...))
+				continue
+			}
+			span.AddEvent("batch.item.succeeded", trace.WithAttributes(eventAttrs...))
+		}
+
+		span.SetAttributes(attribute.Int("batch.failed_count", len(errs)))
+		if eventsDropped > 0 {
+			span.SetAttributes(attribute.Int("batch.events_dropped", eventsDropped))
+		}
+		return errors.Join(errs...)
+	}, append([]TraceOption{WithLinks(links...), WithAttributes(attrs...)}, opts...)...)
+}
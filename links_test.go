@@ -0,0 +1,143 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// producedItems starts and ends one span per ID, returning items that point at them
+// alternately by span context and by propagated headers.
+func producedItems(kit *OTelKit, ids ...string) []BatchItem {
+	items := make([]BatchItem, len(ids))
+	for i, id := range ids {
+		_, span := kit.StartSpan(context.Background(), "produce "+id)
+		span.End()
+		items[i] = BatchItem{ID: id, SpanContext: span.SpanContext()}
+		if i%2 == 1 {
+			headers := MessageHeaders{}
+			kit.textMapPropagator().Inject(trace.ContextWithSpanContext(context.Background(), span.SpanContext()), headers)
+			items[i] = BatchItem{ID: id, Carrier: headers}
+		}
+	}
+	return items
+}
+
+func TestStartSpanWithLinks(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "links-test", MaxSpanLinks: 2})
+	items := producedItems(kit, "a", "b", "c")
+	items = append(items, BatchItem{ID: "untraced"})
+	recorder.Reset()
+
+	_, span := kit.StartSpanWithLinks(context.Background(), "fan_in", items)
+	span.End()
+
+	ended := findSpan(t, recorder.Ended(), "fan_in")
+	links := ended.Links()
+	if len(links) != 2 {
+		t.Fatalf("Expected 2 links (capped), got %d", len(links))
+	}
+	for i, link := range links {
+		attrs := attributeMap(link.Attributes)
+		if attrs["batch.item.id"] != items[i].ID || attrs["batch.item.index"] != fmt.Sprint(i) {
+			t.Errorf("Unexpected link %d attributes: %v", i, attrs)
+		}
+	}
+	if !links[1].SpanContext.IsRemote() {
+		t.Error("Expected link extracted from headers to be remote")
+	}
+	if dropped := attributeMap(ended.Attributes())["batch.links_dropped"]; dropped != "1" {
+		t.Errorf("Expected batch.links_dropped=1, got %q", dropped)
+	}
+}
+
+func TestBatchOperationWithLinks(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "links-test"})
+	items := producedItems(kit, "ok-1", "bad-1", "ok-2", "bad-2")
+	recorder.Reset()
+
+	var processed []string
+	err := kit.BatchOperationWithLinks(context.Background(), "import", items, func(ctx context.Context, item BatchItem) error {
+		processed = append(processed, item.ID)
+		if strings.HasPrefix(item.ID, "bad") {
+			return errors.New("invalid record")
+		}
+		return nil
+	})
+
+	if len(processed) != 4 {
+		t.Errorf("Expected every item to be processed, got %v", processed)
+	}
+	if err == nil || !strings.Contains(err.Error(), "batch item bad-1: invalid record") || !strings.Contains(err.Error(), "batch item bad-2") {
+		t.Errorf("Unexpected joined error: %v", err)
+	}
+
+	span := findSpan(t, recorder.Ended(), "batch.import")
+	attrs := attributeMap(span.Attributes())
+	if attrs["batch.failed_count"] != "2" || attrs["batch.item_count"] != "4" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+	if len(span.Links()) != 4 {
+		t.Errorf("Expected 4 links, got %d", len(span.Links()))
+	}
+
+	var outcomes []string
+	for _, event := range span.Events() {
+		if strings.HasPrefix(event.Name, "batch.item.") {
+			outcomes = append(outcomes, event.Name+":"+attributeMap(event.Attributes)["batch.item.id"])
+		}
+	}
+	expected := "batch.item.succeeded:ok-1 batch.item.failed:bad-1 batch.item.succeeded:ok-2 batch.item.failed:bad-2"
+	if got := strings.Join(outcomes, " "); got != expected {
+		t.Errorf("Unexpected item events:\n expected %s\n got      %s", expected, got)
+	}
+}
+
+func TestBatchOperationWithLinksEventCap(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "links-test"})
+	items := make([]BatchItem, maxBatchItemEvents+5)
+	for i := range items {
+		items[i] = BatchItem{ID: fmt.Sprintf("item-%d", i)}
+	}
+
+	processed := 0
+	err := kit.BatchOperationWithLinks(context.Background(), "import", items, func(ctx context.Context, item BatchItem) error {
+		processed++
+		if item.ID == fmt.Sprintf("item-%d", len(items)-1) {
+			return errors.New("invalid record")
+		}
+		return nil
+	})
+
+	if processed != len(items) {
+		t.Errorf("Expected %d items processed, got %d", len(items), processed)
+	}
+	if err == nil {
+		t.Error("Expected the failure past the event cap to be returned")
+	}
+
+	span := findSpan(t, recorder.Ended(), "batch.import")
+	events, exceptions := 0, 0
+	for _, event := range span.Events() {
+		switch {
+		case strings.HasPrefix(event.Name, "batch.item."):
+			events++
+		case event.Name == "exception":
+			exceptions++
+		}
+	}
+	if events != maxBatchItemEvents || exceptions != 1 {
+		t.Errorf("Expected %d item events and 1 exception event, got %d and %d", maxBatchItemEvents, events, exceptions)
+	}
+	if span.DroppedEvents() != 0 {
+		t.Errorf("Expected the SDK to drop no events, got %d", span.DroppedEvents())
+	}
+	attrs := attributeMap(span.Attributes())
+	if attrs["batch.events_dropped"] != "5" || attrs["batch.failed_count"] != "1" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+}
@@ -0,0 +1,167 @@
+package otelkit
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MessageHeaders is a header carrier for messages whose headers are plain
+// string maps. It implements propagation.TextMapCarrier; for other header
+// types (http.Header, Kafka or AMQP headers) pass any TextMapCarrier adapter,
+// e.g. propagation.HeaderCarrier.
+type MessageHeaders map[string]string
+
+// Get returns the value stored for key.
+func (h MessageHeaders) Get(key string) string {
+	return h[key]
+}
+
+// Set stores value under key.
+func (h MessageHeaders) Set(key, value string) {
+	h[key] = value
+}
+
+// Keys lists the header keys.
+func (h MessageHeaders) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for key := range h {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// Publish traces sending a message and injects the trace context into its headers.
+//
+// Parameters:
+//   - ctx: Context for the operation (may contain parent span)
+//   - system: The messaging system (e.g., "kafka", "rabbitmq", "aws_sqs")
+//   - destination: The queue or topic name
+//   - headers: Carrier the trace context is injected into before fn runs (may be nil)
+//   - fn: The function that sends the message, including the injected headers
+//   - opts: Optional span settings (e.g., WithAttributes(semconv.MessagingMessageID(id)))
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// The span "send <destination>" is created with SpanKindProducer and these attributes:
+//   - messaging.system: The messaging system
+//   - messaging.destination.name: The destination
+//   - messaging.operation.name: "send"
+//   - messaging.operation.type: "send"
+//
+// Example:
+//   headers := otelkit.MessageHeaders{}
+//   err := kit.Publish(ctx, "rabbitmq", "orders", headers, func(ctx context.Context) error {
+//       return ch.PublishWithContext(ctx, "", "orders", false, false, toAMQP(body, headers))
+//   })
+func (o *OTelKit) Publish(ctx context.Context, system, destination string, headers propagation.TextMapCarrier, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	return o.traceWithOptions(ctx, "send "+destination, func(ctx context.Context) error {
+		if headers != nil {
+			o.textMapPropagator().Inject(ctx, headers)
+		}
+		return fn(ctx)
+	}, append([]TraceOption{WithSpanKind(trace.SpanKindProducer), WithAttributes(
+		semconv.MessagingSystemKey.String(system),
+		semconv.MessagingDestinationName(destination),
+		semconv.MessagingOperationName("send"),
+		semconv.MessagingOperationTypeSend,
+	)}, opts...)...)
+}
+
+// Consume traces processing a received message as a continuation of the trace
+// that published it.
+//
+// Parameters:
+//   - ctx: Context for the operation
+//   - system: The messaging system (e.g., "kafka", "rabbitmq", "aws_sqs")
+//   - destination: The queue or topic the message was received from
+//   - headers: Carrier the producer's trace context is extracted from (may be nil)
+//   - fn: The function that processes the message
+//   - opts: Optional span settings
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// The span "process <destination>" is created with SpanKindConsumer as a child of
+// the producer span found in headers, with the same messaging.* attributes as
+// Publish and messaging.operation.name "process".
+//
+// Example:
+//   for msg := range deliveries {
+//       err := kit.Consume(ctx, "rabbitmq", "orders", amqpHeaders(msg.Headers), func(ctx context.Context) error {
+//           return handleOrder(ctx, msg.Body)
+//       })
+//   }
+func (o *OTelKit) Consume(ctx context.Context, system, destination string, headers propagation.TextMapCarrier, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	if headers != nil {
+		ctx = o.textMapPropagator().Extract(ctx, headers)
+	}
+	return o.traceWithOptions(ctx, "process "+destination, fn,
+		append([]TraceOption{WithSpanKind(trace.SpanKindConsumer), WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(destination),
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingOperationTypeProcess,
+		)}, opts...)...,
+	)
+}
+
+// ConsumeBatch traces processing a batch of received messages. Because the
+// messages belong to different traces, the span stays in the caller's trace
+// and links to the producer span of every message instead.
+//
+// Parameters:
+//   - ctx: Context for the operation (may contain parent span, e.g. a poll loop)
+//   - system: The messaging system (e.g., "kafka", "rabbitmq", "aws_sqs")
+//   - destination: The queue or topic the messages were received from
+//   - messages: The header carriers of the received messages
+//   - fn: The function that processes the batch
+//   - opts: Optional span settings
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// The span "process <destination>" is created with SpanKindConsumer, the
+// messaging.* attributes of Consume and messaging.batch.message_count.
+// Messages without a valid trace context are not linked, and at most
+// Config.MaxSpanLinks messages are.
+//
+// Example:
+//   batch := queue.Receive(100)
+//   carriers := make([]propagation.TextMapCarrier, len(batch))
+//   for i, msg := range batch {
+//       carriers[i] = msg.Headers
+//   }
+//   err := kit.ConsumeBatch(ctx, "aws_sqs", "orders", carriers, func(ctx context.Context) error {
+//       return handleOrders(ctx, batch)
+//   })
+func (o *OTelKit) ConsumeBatch(ctx context.Context, system, destination string, messages []propagation.TextMapCarrier, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	items := make([]BatchItem, len(messages))
+	for i, headers := range messages {
+		items[i] = BatchItem{Carrier: headers}
+	}
+	links, _ := o.batchLinks(items)
+
+	return o.traceWithOptions(ctx, "process "+destination, fn,
+		append([]TraceOption{WithSpanKind(trace.SpanKindConsumer), WithLinks(links...), WithAttributes(
+			semconv.MessagingSystemKey.String(system),
+			semconv.MessagingDestinationName(destination),
+			semconv.MessagingOperationName("process"),
+			semconv.MessagingOperationTypeProcess,
+			semconv.MessagingBatchMessageCount(len(messages)),
+		)}, opts...)...,
+	)
+}
+
+// textMapPropagator returns the propagator used for message headers: the one
+// installed by initTracing, or the global propagator.
+func (o *OTelKit) textMapPropagator() propagation.TextMapPropagator {
+	if o.propagator != nil {
+		return o.propagator
+	}
+	return otel.GetTextMapPropagator()
+}
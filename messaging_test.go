@@ -0,0 +1,130 @@
+package otelkit
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queueMessage is a message carried by memQueue.
+type queueMessage struct {
+	Body    string
+	Headers MessageHeaders
+}
+
+// memQueue is an in-memory FIFO queue standing in for a broker.
+type memQueue struct {
+	mu       sync.Mutex
+	messages []queueMessage
+}
+
+func (q *memQueue) Send(msg queueMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.messages = append(q.messages, msg)
+}
+
+// Receive removes and returns up to max messages.
+func (q *memQueue) Receive(max int) []queueMessage {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := min(max, len(q.messages))
+	batch := q.messages[:n]
+	q.messages = q.messages[n:]
+	return batch
+}
+
+func TestMessaging(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "messaging-test"})
+	queue := &memQueue{}
+	ctx := context.Background()
+
+	// Each message is published from its own trace
+	var producers []trace.SpanContext
+	for _, body := range []string{"order-1", "order-2", "order-3"} {
+		headers := MessageHeaders{}
+		err := kit.Publish(ctx, "memory", "orders", headers, func(ctx context.Context) error {
+			producers = append(producers, trace.SpanContextFromContext(ctx))
+			queue.Send(queueMessage{Body: body, Headers: headers})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+
+	t.Run("Publish", func(t *testing.T) {
+		span := findSpan(t, recorder.Ended(), "send orders")
+		if span.SpanKind() != trace.SpanKindProducer {
+			t.Errorf("Expected producer span, got %v", span.SpanKind())
+		}
+		attrs := attributeMap(span.Attributes())
+		if attrs["messaging.system"] != "memory" || attrs["messaging.destination.name"] != "orders" || attrs["messaging.operation.type"] != "send" {
+			t.Errorf("Unexpected attributes: %v", attrs)
+		}
+	})
+
+	t.Run("Consume", func(t *testing.T) {
+		recorder.Reset()
+		msg := queue.Receive(1)[0]
+		if msg.Headers["traceparent"] == "" {
+			t.Fatalf("traceparent not injected: %v", msg.Headers)
+		}
+
+		err := kit.Consume(ctx, "memory", "orders", msg.Headers, func(ctx context.Context) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Consume failed: %v", err)
+		}
+
+		span := findSpan(t, recorder.Ended(), "process orders")
+		if span.SpanKind() != trace.SpanKindConsumer {
+			t.Errorf("Expected consumer span, got %v", span.SpanKind())
+		}
+		if span.Parent().SpanID() != producers[0].SpanID() || span.SpanContext().TraceID() != producers[0].TraceID() {
+			t.Errorf("Consumer span not a child of the producer span")
+		}
+		if op := attributeMap(span.Attributes())["messaging.operation.type"]; op != "process" {
+			t.Errorf("Expected operation type process, got %q", op)
+		}
+	})
+
+	t.Run("ConsumeBatch", func(t *testing.T) {
+		recorder.Reset()
+		batch := queue.Receive(10)
+		carriers := make([]propagation.TextMapCarrier, 0, len(batch)+1)
+		for _, msg := range batch {
+			carriers = append(carriers, msg.Headers)
+		}
+		// Messages from uninstrumented producers are not linked
+		carriers = append(carriers, MessageHeaders{})
+
+		err := kit.ConsumeBatch(ctx, "memory", "orders", carriers, func(ctx context.Context) error {
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("ConsumeBatch failed: %v", err)
+		}
+
+		span := findSpan(t, recorder.Ended(), "process orders")
+		if span.Parent().IsValid() {
+			t.Error("Batch span must not continue a producer trace")
+		}
+		links := span.Links()
+		if len(links) != 2 {
+			t.Fatalf("Expected 2 links, got %d", len(links))
+		}
+		for i, link := range links {
+			if link.SpanContext.SpanID() != producers[i+1].SpanID() {
+				t.Errorf("Link %d does not point at its producer span", i)
+			}
+		}
+		if count := attributeMap(span.Attributes())["messaging.batch.message_count"]; count != "3" {
+			t.Errorf("Expected message_count 3, got %q", count)
+		}
+	})
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
 	"strconv"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -21,35 +24,61 @@ import (
 //   - http.Handler: A wrapped handler that creates telemetry for each HTTP request
 //
 // The middleware automatically captures:
-//   - HTTP traces with method, URL, status codes, and timing
+//   - HTTP server spans (SpanKindServer) with method, URL, status codes, and timing
 //   - Structured logs with request/response details and trace correlation
 //   - Metrics for request counts, duration histograms, and error rates
 //   - Error status for 4xx/5xx responses
+//   - Panics in the handler, recorded as an exception event with stack trace and a 500
+//
+// After a panic has been recorded the middleware re-panics, unless
+// Config.RecoverPanics is set, in which case it responds with 500.
 //
 // Telemetry includes:
 //   - Traces: HTTP method, URL, status code, duration, user agent, remote address
 //   - Logs: Request start/end, errors, structured context with trace correlation
 //   - Metrics: http_requests_total counter, http_request_duration_seconds histogram
 func (o *OTelKit) HTTPMiddleware(next http.Handler) http.Handler {
+	return o.HTTPMiddlewareWithOptions()(next)
+}
+
+// HTTPMiddlewareWithOptions returns HTTPMiddleware configured with span options.
+//
+// Parameters:
+//   - opts: Span settings applied after the defaults (e.g., WithSpanKind, WithAttributes)
+//
+// Returns:
+//   - func(http.Handler) http.Handler: A middleware constructor
+//
+// Request spans default to SpanKindServer. Override the kind when the handler is
+// not the entry point of a remote call, e.g. an in-process adapter.
+//
+// Example:
+//   handler := kit.HTTPMiddlewareWithOptions(otelkit.WithAttributes(attribute.String("api.version", "v2")))(mux)
+func (o *OTelKit) HTTPMiddlewareWithOptions(opts ...TraceOption) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return o.httpMiddleware(next, opts)
+	}
+}
+
+// httpMiddleware builds the handler shared by HTTPMiddleware and HTTPMiddlewareWithOptions.
+func (o *OTelKit) httpMiddleware(next http.Handler, opts []TraceOption) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		
 		// Start tracing
-		ctx, span := o.StartSpan(r.Context(), r.Method+" "+r.URL.Path,
-			trace.WithAttributes(
+		cfg := newTraceConfig(append([]TraceOption{
+			WithSpanKind(trace.SpanKindServer),
+			WithAttributes(
 				attribute.String("http.method", r.Method),
 				attribute.String("http.url", r.URL.String()),
 				attribute.String("http.route", r.URL.Path),
 				attribute.String("http.user_agent", r.UserAgent()),
 				attribute.String("http.remote_addr", r.RemoteAddr),
 			),
-		)
+		}, opts...))
+		ctx, span := o.StartSpan(r.Context(), r.Method+" "+r.URL.Path, cfg.spanStartOptions()...)
 		defer span.End()
 
-		// Track active spans
-		o.IncrementActiveSpans(ctx)
-		defer o.DecrementActiveSpans(ctx)
-
 		// Log request start
 		o.LogInfo(ctx, "HTTP request started",
 			slog.String("method", r.Method),
@@ -61,53 +90,84 @@ func (o *OTelKit) HTTPMiddleware(next http.Handler) http.Handler {
 		// Create a response writer wrapper to capture status code
 		wrapped := &responseWriter{ResponseWriter: w, statusCode: 200}
 
+		// Record panics from the handler as a 500 before the span ends
+		defer func() {
+			if rec := recover(); rec != nil {
+				// http.ErrAbortHandler must always reach net/http to abort the response
+				escaped := !o.config.RecoverPanics || rec == http.ErrAbortHandler
+
+				o.recordPanic(ctx, span, rec, debug.Stack(), escaped)
+				wrapped.statusCode = http.StatusInternalServerError
+				o.finishHTTPRequest(ctx, span, r, wrapped.statusCode, time.Since(start))
+
+				if escaped {
+					panic(rec)
+				}
+				if !wrapped.wroteHeader {
+					wrapped.WriteHeader(http.StatusInternalServerError)
+				}
+			}
+		}()
+
 		// Execute the handler with the traced context
 		next.ServeHTTP(wrapped, r.WithContext(ctx))
 
-		// Calculate duration
-		duration := time.Since(start)
-		statusCode := strconv.Itoa(wrapped.statusCode)
+		o.finishHTTPRequest(ctx, span, r, wrapped.statusCode, time.Since(start))
+	})
+}
 
-		// Add response attributes to span
-		span.SetAttributes(
-			attribute.Int("http.status_code", wrapped.statusCode),
-			attribute.String("http.status_text", http.StatusText(wrapped.statusCode)),
-			attribute.Float64("http.duration_ms", float64(duration.Nanoseconds())/1e6),
-		)
+// finishHTTPRequest records the response attributes, metrics and logs for a completed request.
+//
+// Parameters:
+//   - ctx: Context containing the request span
+//   - span: The request span
+//   - r: The incoming HTTP request
+//   - status: The HTTP status code sent (or 500 for a panicking handler)
+//   - duration: Time spent serving the request
+func (o *OTelKit) finishHTTPRequest(ctx context.Context, span trace.Span, r *http.Request, status int, duration time.Duration) {
+	statusCode := strconv.Itoa(status)
 
-		// Set span status based on HTTP status code
-		if wrapped.statusCode >= 400 {
-			span.SetStatus(codes.Error, http.StatusText(wrapped.statusCode))
-		}
+	// Add response attributes to span
+	span.SetAttributes(
+		attribute.Int("http.status_code", status),
+		attribute.String("http.status_text", http.StatusText(status)),
+		attribute.Float64("http.duration_ms", float64(duration.Nanoseconds())/1e6),
+	)
 
-		// Record metrics
-		o.RecordHTTPMetrics(ctx, r.Method, statusCode, duration)
+	// Set span status based on HTTP status code
+	if status >= 400 {
+		span.SetStatus(codes.Error, http.StatusText(status))
+	}
 
-		// Log request completion
-		logLevel := slog.LevelInfo
-		if wrapped.statusCode >= 500 {
-			logLevel = slog.LevelError
-		} else if wrapped.statusCode >= 400 {
-			logLevel = slog.LevelWarn
-		}
+	// Record metrics
+	o.RecordHTTPMetrics(ctx, r.Method, statusCode, duration)
+
+	// Log request completion
+	logLevel := slog.LevelInfo
+	if status >= 500 {
+		logLevel = slog.LevelError
+	} else if status >= 400 {
+		logLevel = slog.LevelWarn
+	}
 
+	if o.logger != nil {
 		o.logger.LogAttrs(ctx, logLevel, "HTTP request completed",
 			slog.String("method", r.Method),
 			slog.String("path", r.URL.Path),
-			slog.Int("status_code", wrapped.statusCode),
-			slog.String("status_text", http.StatusText(wrapped.statusCode)),
+			slog.Int("status_code", status),
+			slog.String("status_text", http.StatusText(status)),
 			slog.Float64("duration_ms", float64(duration.Nanoseconds())/1e6),
 		)
+	}
 
-		// Log errors for 4xx/5xx responses
-		if wrapped.statusCode >= 400 {
-			o.LogError(ctx, "HTTP request failed",
-				nil, // No underlying error, just HTTP status
-				slog.Int("status_code", wrapped.statusCode),
-				slog.String("path", r.URL.Path),
-			)
-		}
-	})
+	// Log errors for 4xx/5xx responses
+	if status >= 400 {
+		o.LogError(ctx, "HTTP request failed",
+			nil, // No underlying error, just HTTP status
+			slog.Int("status_code", status),
+			slog.String("path", r.URL.Path),
+		)
+	}
 }
 
 // responseWriter wraps http.ResponseWriter to capture the status code.
@@ -117,9 +177,11 @@ func (o *OTelKit) HTTPMiddleware(next http.Handler) http.Handler {
 // Fields:
 //   - ResponseWriter: The underlying http.ResponseWriter
 //   - statusCode: The HTTP status code (defaults to 200)
+//   - wroteHeader: Whether the header has been sent to the client
 type responseWriter struct {
 	http.ResponseWriter
-	statusCode int
+	statusCode  int
+	wroteHeader bool
 }
 
 // WriteHeader captures the status code before forwarding to the underlying writer.
@@ -128,30 +190,92 @@ type responseWriter struct {
 //   - statusCode: The HTTP status code to write (200, 404, 500, etc.)
 func (w *responseWriter) WriteHeader(statusCode int) {
 	w.statusCode = statusCode
+	w.wroteHeader = true
 	w.ResponseWriter.WriteHeader(statusCode)
 }
 
+// Write marks the header as sent before forwarding to the underlying writer.
+//
+// Parameters:
+//   - b: The response body bytes to write
+//
+// Returns:
+//   - int: Number of bytes written
+//   - error: Any error from the underlying writer
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
 // DatabaseOperation traces and logs a database operation with standardized attributes.
 //
 // Parameters:
 //   - ctx: Context for the operation (will be enriched with span context)
-//   - operation: The database operation type (SELECT, INSERT, UPDATE, DELETE, etc.)
-//   - table: The database table name being operated on
+//   - operation: The database operation type (SELECT, INSERT, UPDATE, DELETE, etc.),
+//     or the full SQL statement being executed
+//   - table: The database table name being operated on (may be empty when operation is a statement)
 //   - fn: The function to execute that performs the database operation
 //
 // Returns:
 //   - error: Any error returned by the fn function
 //
-// This function automatically adds the following span attributes:
+// The span is created with SpanKindClient. This function automatically adds the following span attributes:
 //   - db.operation: The operation type
 //   - db.table: The table name
 //   - db.type: Database type (defaults to "unknown", can be overridden in fn)
+//   - db.query.text: The statement sanitized by SanitizeSQL (only when a statement is passed)
+//
+// When operation is a full SQL statement it is parsed with ParseSQL: the operation
+// and (if table is empty) the table are derived from it, and only the sanitized
+// text is recorded, so literal values never reach spans, logs or metrics.
+//
+// Example:
+//   err := kit.DatabaseOperation(ctx, "SELECT * FROM users WHERE id = 42", "", fn)
+//   // span "db.SELECT" with db.table=users and db.query.text="SELECT * FROM users WHERE id = ?"
 //
 // Additionally provides structured logging with:
 //   - Operation start/completion logs with trace correlation
 //   - Error logging for failed operations
 //   - Performance timing information
+//
+// The duration is recorded in the db.client.operation.duration histogram. The
+// db.system.name label is taken from a db.system.name or db.system attribute
+// passed to DatabaseOperationWithOptions, and is "unknown" otherwise.
 func (o *OTelKit) DatabaseOperation(ctx context.Context, operation, table string, fn func(ctx context.Context) error) error {
+	return o.DatabaseOperationWithOptions(ctx, operation, table, fn)
+}
+
+// DatabaseOperationWithOptions is DatabaseOperation with span options.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - operation: The database operation type or the full SQL statement being executed
+//   - table: The database table name being operated on (may be empty when operation is a statement)
+//   - fn: The function to execute that performs the database operation
+//   - opts: Span settings applied after the defaults (e.g., WithSpanKind, WithAttributes)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// A db.system.name attribute also selects the SQL dialect used to sanitize the
+// statement, so MySQL and MariaDB double-quoted strings are masked.
+//
+// Example:
+//   err := kit.DatabaseOperationWithOptions(ctx, query, "", fn,
+//       otelkit.WithAttributes(semconv.DBSystemNameMySQL),
+//   )
+func (o *OTelKit) DatabaseOperationWithOptions(ctx context.Context, operation, table string, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	// Derive operation and table from a full statement
+	var queryAttrs []attribute.KeyValue
+	if strings.ContainsAny(strings.TrimSpace(operation), " \t\r\n") {
+		stmt := parseSQL(operation, dbSystemFromOptions(opts))
+		if table == "" {
+			table = stmt.Table()
+		}
+		operation = stmt.Operation
+		queryAttrs = append(queryAttrs, semconv.DBQueryText(stmt.Sanitized))
+	}
+
 	// Log operation start
 	o.LogDebug(ctx, "Database operation started",
 		slog.String("operation", operation),
@@ -160,11 +284,12 @@ func (o *OTelKit) DatabaseOperation(ctx context.Context, operation, table string
 
 	start := time.Now()
 	
-	err := o.TraceFunction(ctx, "db."+operation,
-		fn,
-		attribute.String("db.operation", operation),
-		attribute.String("db.table", table),
-		attribute.String("db.type", "unknown"), // Can be overridden
+	err := o.traceWithOptions(ctx, "db."+operation, fn,
+		append([]TraceOption{WithSpanKind(trace.SpanKindClient), WithAttributes(
+			attribute.String("db.operation", operation),
+			attribute.String("db.table", table),
+			attribute.String("db.type", "unknown"), // Can be overridden
+		), WithAttributes(queryAttrs...)}, opts...)...,
 	)
 
 	duration := time.Since(start)
@@ -190,6 +315,7 @@ func (o *OTelKit) DatabaseOperation(ctx context.Context, operation, table string
 		attribute.String("db.table", table),
 		attribute.Bool("success", err == nil),
 	)
+	o.recordDBOperation(ctx, dbSystemFromOptions(opts), operation, table, duration, err)
 
 	return err
 }
@@ -205,14 +331,33 @@ func (o *OTelKit) DatabaseOperation(ctx context.Context, operation, table string
 // Returns:
 //   - error: Any error returned by the fn function
 //
-// This function automatically adds the following span attributes:
+// The span is created with SpanKindClient. This function automatically adds the following span attributes:
 //   - cache.operation: The operation type
 //   - cache.key: The cache key
+//
+// CacheOperation records the raw key and no hit/miss outcome; prefer CacheRequest
+// or InstrumentCache, which hash the key and record cache metrics.
 func (o *OTelKit) CacheOperation(ctx context.Context, operation, key string, fn func(ctx context.Context) error) error {
-	return o.TraceFunction(ctx, "cache."+operation,
-		fn,
-		attribute.String("cache.operation", operation),
-		attribute.String("cache.key", key),
+	return o.CacheOperationWithOptions(ctx, operation, key, fn)
+}
+
+// CacheOperationWithOptions is CacheOperation with span options.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - operation: The cache operation type (get, set, delete, flush, etc.)
+//   - key: The cache key being operated on
+//   - fn: The function to execute that performs the cache operation
+//   - opts: Span settings applied after the defaults (e.g., WithSpanKind, WithAttributes)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+func (o *OTelKit) CacheOperationWithOptions(ctx context.Context, operation, key string, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	return o.traceWithOptions(ctx, "cache."+operation, fn,
+		append([]TraceOption{WithSpanKind(trace.SpanKindClient), WithAttributes(
+			attribute.String("cache.operation", operation),
+			attribute.String("cache.key", key),
+		)}, opts...)...,
 	)
 }
 
@@ -227,16 +372,39 @@ func (o *OTelKit) CacheOperation(ctx context.Context, operation, key string, fn
 // Returns:
 //   - error: Any error returned by the fn function
 //
-// This function automatically adds the following span attributes:
+// The span is created with SpanKindClient. This function automatically adds the following span attributes:
+//   - peer.service: The external service name (used by backends to draw the service map)
 //   - service.name: The external service name
 //   - service.operation: The operation being performed
 //
 // The span name will be formatted as "external.{serviceName}.{operation}"
 func (o *OTelKit) ExternalServiceCall(ctx context.Context, serviceName, operation string, fn func(ctx context.Context) error) error {
-	return o.TraceFunction(ctx, "external."+serviceName+"."+operation,
-		fn,
-		attribute.String("service.name", serviceName),
-		attribute.String("service.operation", operation),
+	return o.ExternalServiceCallWithOptions(ctx, serviceName, operation, fn)
+}
+
+// ExternalServiceCallWithOptions is ExternalServiceCall with span options.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - serviceName: Name of the external service
+//   - operation: The operation being performed
+//   - fn: The function to execute that makes the external service call
+//   - opts: Span settings applied after the defaults (e.g., WithSpanKind, WithAttributes)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// Example:
+//   err := kit.ExternalServiceCallWithOptions(ctx, "orders", "publish", fn,
+//       otelkit.WithSpanKind(trace.SpanKindProducer),
+//   )
+func (o *OTelKit) ExternalServiceCallWithOptions(ctx context.Context, serviceName, operation string, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	return o.traceWithOptions(ctx, "external."+serviceName+"."+operation, fn,
+		append([]TraceOption{WithSpanKind(trace.SpanKindClient), WithAttributes(
+			attribute.String("peer.service", serviceName),
+			attribute.String("service.name", serviceName),
+			attribute.String("service.operation", operation),
+		)}, opts...)...,
 	)
 }
 
@@ -289,10 +457,26 @@ func (o *OTelKit) TimedOperation(ctx context.Context, operationName string, fn f
 // The span name will be formatted as "batch.{operationName}"
 // Use this for operations that process multiple items at once.
 func (o *OTelKit) BatchOperation(ctx context.Context, operationName string, itemCount int, fn func(ctx context.Context) error) error {
-	return o.TraceFunction(ctx, "batch."+operationName,
-		fn,
-		attribute.String("batch.operation", operationName),
-		attribute.Int("batch.item_count", itemCount),
+	return o.BatchOperationWithOptions(ctx, operationName, itemCount, fn)
+}
+
+// BatchOperationWithOptions is BatchOperation with span options.
+//
+// Parameters:
+//   - ctx: Context for the operation (will be enriched with span context)
+//   - operationName: Name of the batch operation
+//   - itemCount: Number of items being processed in the batch
+//   - fn: The function to execute that performs the batch operation
+//   - opts: Span settings applied after the defaults (kind, links, attributes, start time)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+func (o *OTelKit) BatchOperationWithOptions(ctx context.Context, operationName string, itemCount int, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	return o.traceWithOptions(ctx, "batch."+operationName, fn,
+		append([]TraceOption{WithAttributes(
+			attribute.String("batch.operation", operationName),
+			attribute.Int("batch.item_count", itemCount),
+		)}, opts...)...,
 	)
 }
 
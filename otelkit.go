@@ -6,6 +6,10 @@ import (
 	"log"
 	"log/slog"
 	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -14,12 +18,15 @@ import (
 	"go.opentelemetry.io/otel/exporters/jaeger"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
-	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 	"go.opentelemetry.io/otel/trace"
 	
 	// Metrics
+	"github.com/prometheus/otlptranslator"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
 	"go.opentelemetry.io/otel/exporters/prometheus"
 	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
@@ -97,6 +104,145 @@ type Config struct {
 	// If empty, logs will only go to stdout and OTLP (if configured)
 	// Example: "/var/log/app.log", "./logs/service.log"
 	LogFilePath string
+	
+	// RecoverPanics controls what happens after a panic has been recorded on a span
+	// When true, HTTPMiddleware responds with 500 and TraceFunction returns a *PanicError
+	// When false (default), the panic is re-raised once telemetry has been captured
+	RecoverPanics bool
+	
+	// MaxSpanLinks caps the links StartSpanWithLinks and BatchOperationWithLinks
+	// attach to one span; links beyond the cap are counted in batch.links_dropped
+	// 0 uses the default of 128, the SDK's own per-span link limit
+	MaxSpanLinks int
+	
+	// SpanLeakThreshold enables leak detection: spans still open after this long are
+	// logged as a warning with their name and creation stack trace, as are spans
+	// still open at Shutdown; SpanDebugHandler lists them on demand
+	// 0 (default) disables it; capturing creation stacks adds a little overhead to every span
+	// Example: 5 * time.Minute
+	SpanLeakThreshold time.Duration
+	
+	// SpanMetrics derives calls_total and duration (seconds) from every
+	// ended span, labelled by span name, kind and status (requires EnableMetrics)
+	// Spans dropped by SampleRate are still recorded for the metrics, but not exported
+	SpanMetrics bool
+	
+	// SpanMetricsAttributes lists span attributes added as labels to the span metrics
+	// Keep it to low-cardinality attributes
+	// Example: []string{"db.system.name", "messaging.system", "batch.operation"}
+	SpanMetricsAttributes []string
+	
+	// SpanMetricsMaxSeries caps the distinct label sets of the span metrics; spans
+	// beyond it are recorded under span.name="_other"
+	// 0 uses the default of 1000
+	SpanMetricsMaxSeries int
+	
+	// ExemplarFilter selects the measurements recorded as exemplars (trace links on metrics)
+	// Options: ExemplarFilterTraceBased (default), ExemplarFilterAlwaysOn, ExemplarFilterAlwaysOff
+	ExemplarFilter ExemplarFilter
+	
+	// MetricViews customize metrics before export: histogram buckets or exponential
+	// histograms, attribute allow/deny lists, renames and drops
+	// Example: []otelkit.MetricView{{Instrument: "http_request_duration_seconds",
+	//   Aggregation: otelkit.AggregationExplicitHistogram, Buckets: []float64{0.001, 0.005, 0.01}}}
+	MetricViews []MetricView
+	
+	// MetricViewsFile is a YAML or JSON file of additional views, applied after MetricViews
+	// See LoadMetricViews for the format
+	MetricViewsFile string
+	
+	// ExponentialHistograms records every histogram (http_request_duration_seconds and
+	// all other otelkit and custom histograms) as a base-2 exponential histogram,
+	// exported as an OTLP exponential histogram or a Prometheus native histogram
+	// Override single instruments with a MetricView
+	ExponentialHistograms bool
+	
+	// ExponentialHistogramMaxSize is the maximum number of buckets per exponential histogram
+	// 0 uses the default of 160
+	ExponentialHistogramMaxSize int32
+	
+	// ExponentialHistogramMaxScale is the maximum (initial) resolution of exponential
+	// histograms, from -10 to 20; it is lowered automatically to fit MaxSize
+	// 0 uses the default of 20
+	ExponentialHistogramMaxScale int32
+	
+	// MetricCardinalityLimit caps the distinct attribute sets of each synchronous
+	// instrument; measurements with new attribute sets beyond it are recorded
+	// under otel.metric.overflow=true, logged once and counted by
+	// otelkit_metric_cardinality_overflow_total
+	// 0 uses the default of 2000, negative disables the limit
+	MetricCardinalityLimit int
+	
+	// MetricCardinalityLimits overrides MetricCardinalityLimit per instrument name
+	// Example: map[string]int{"http_requests_total": 500, "orders_total": -1}
+	MetricCardinalityLimits map[string]int
+	
+	// RuntimeMetrics reports Go runtime metrics: goroutines, memory, heap objects,
+	// GC cycles and pause latency, and scheduler latency (go.* instruments)
+	RuntimeMetrics bool
+	
+	// ProcessMetrics reports process CPU time, resident and virtual memory, open
+	// file descriptors, threads and uptime (process.* instruments); Linux only
+	ProcessMetrics bool
+	
+	// MetricExportInterval is how often the OTLP and stdout metrics exporters push
+	// 0 uses the default of 15s
+	MetricExportInterval time.Duration
+	
+	// MetricExportTimeout bounds each push of the OTLP and stdout metrics exporters
+	// 0 uses the default of 30s
+	MetricExportTimeout time.Duration
+	
+	// MetricTemporality selects cumulative or delta reporting for the OTLP and
+	// stdout metrics exporters; Prometheus is always cumulative
+	// Options: MetricTemporalityCumulative (default), MetricTemporalityDelta, MetricTemporalityLowMemory
+	MetricTemporality MetricTemporality
+	
+	// MetricTemporalityByKind overrides MetricTemporality per instrument kind
+	// (InstrumentKindCounter, InstrumentKindHistogram, ...) with cumulative or delta
+	// Example: map[string]otelkit.MetricTemporality{otelkit.InstrumentKindHistogram: otelkit.MetricTemporalityCumulative}
+	MetricTemporalityByKind map[string]MetricTemporality
+	
+	// TraceExporters sends traces to several exporters at once, each with its own
+	// span processor; when set, it replaces ExporterType
+	// Example: []otelkit.ExporterConfig{{Type: otelkit.ExporterOTLP}, {Type: otelkit.ExporterStdout, Synchronous: true}}
+	TraceExporters []ExporterConfig
+	
+	// MetricsExporters sends metrics to several exporters at once, each with its
+	// own reader; when set, it replaces MetricsExporterType
+	// Example: []otelkit.ExporterConfig{{Type: otelkit.ExporterPrometheus}, {Type: otelkit.ExporterOTLP}}
+	MetricsExporters []ExporterConfig
+	
+	// LogsExporters sends logs to several exporters at once, each with its own
+	// log processor; when set, it replaces LogsExporterType
+	LogsExporters []ExporterConfig
+	
+	// PersistentQueueDir enables a file-backed queue for the OTLP exporters: export
+	// requests are written to segment files in this directory and delivered in
+	// order, retrying with backoff while the collector is unreachable; requests
+	// left over at shutdown are replayed on the next start
+	// Example: "/var/lib/my-service/otel-queue"
+	PersistentQueueDir string
+	
+	// PersistentQueueMaxBytes caps the disk usage of each OTLP exporter's queue;
+	// the oldest requests are dropped beyond it
+	// 0 uses the default of 256 MiB
+	PersistentQueueMaxBytes int64
+	
+	// SpanProcessor tunes the batch span processor of every trace exporter
+	// (queue size, batch size, schedule delay, export timeout), or makes span
+	// export synchronous for CLI tools and tests
+	// Example: otelkit.BatchProcessorConfig{MaxQueueSize: 8192, ScheduleDelay: time.Second}
+	SpanProcessor BatchProcessorConfig
+	
+	// LogProcessor tunes the batch log processor of every logs exporter, or makes
+	// log export synchronous
+	LogProcessor BatchProcessorConfig
+	
+	// CacheKeyHashKey is the HMAC-SHA256 key CacheRequest hashes cache keys with
+	// Share it between services to correlate keys across them; keep it secret
+	// "" uses a random key per process, so hashes differ between processes
+	CacheKeyHashKey string
 }
 
 // ExporterType defines the type of exporter to use for sending telemetry data.
@@ -139,9 +285,28 @@ type OTelKit struct {
 	// tracerProvider manages the tracer lifecycle and span export
 	tracerProvider *sdktrace.TracerProvider
 	
+	// propagator injects and extracts trace context in message headers
+	propagator propagation.TextMapPropagator
+	
+	// pipeline counts what the exporters send, fail and drop for the
+	// otelkit_exporter_* metrics
+	pipeline *pipelineMetrics
+	
+	// errorHandler logs SDK errors until Shutdown restores the previous handler
+	errorHandler *sdkErrorHandler
+	
+	// activeSpans counts live spans for otelkit_active_spans and detects leaks
+	activeSpans *activeSpanProcessor
+	
+	// spanMetrics derives RED metrics from ended spans (nil unless Config.SpanMetrics)
+	spanMetrics *spanMetricsProcessor
+	
 	// meter is the OpenTelemetry meter instance used to create metrics instruments
 	meter metric.Meter
 	
+	// cardinality limits the series of the instruments created with meter
+	cardinality *cardinalityLimiter
+	
 	// meterProvider manages the meter lifecycle and metrics export
 	meterProvider *sdkmetric.MeterProvider
 	
@@ -157,11 +322,22 @@ type OTelKit struct {
 	// config stores the configuration used to initialize this instance
 	config Config
 	
+	// instruments caches the custom instruments created by Counter, Histogram, Gauge, ...
+	instruments   map[string]*instrumentEntry
+	instrumentsMu sync.Mutex
+	
 	// Common metrics instruments for automatic instrumentation
-	httpRequestDuration metric.Float64Histogram
-	httpRequestsTotal   metric.Int64Counter
-	activeSpansGauge    metric.Int64UpDownCounter
-	businessOpsCounter  metric.Int64Counter
+	httpRequestDuration  metric.Float64Histogram
+	httpRequestsTotal    metric.Int64Counter
+	businessOpsCounter   metric.Int64Counter
+	dbOperationDuration  metric.Float64Histogram
+	cacheRequestsTotal   metric.Int64Counter
+	cacheRequestDuration metric.Float64Histogram
+	batchItemsProcessed  metric.Int64Counter
+	batchItemsFailed     metric.Int64Counter
+	batchDuration        metric.Float64Histogram
+	spanCallsTotal       metric.Int64Counter
+	spanDuration         metric.Float64Histogram
 }
 
 // DefaultConfig returns a default configuration with sensible defaults.
@@ -174,17 +350,41 @@ type OTelKit struct {
 //   - OTEL_SERVICE_NAME: overrides ServiceName
 //   - OTEL_SERVICE_VERSION: overrides ServiceVersion  
 //   - OTEL_ENVIRONMENT: overrides Environment
-//   - OTEL_EXPORTER_TYPE: overrides ExporterType
+//   - OTEL_EXPORTER_TYPE: overrides ExporterType; a comma-separated list sets TraceExporters
 //   - JAEGER_URL: overrides JaegerURL
 //   - OTEL_EXPORTER_OTLP_ENDPOINT: overrides OTLPEndpoint
 //   - OTEL_DEBUG: overrides Debug (set to "true" to enable)
 //   - OTEL_ENABLE_METRICS: overrides EnableMetrics (set to "true" to enable)
 //   - OTEL_ENABLE_LOGS: overrides EnableLogs (set to "true" to enable)
-//   - OTEL_METRICS_EXPORTER: overrides MetricsExporterType
-//   - OTEL_LOGS_EXPORTER: overrides LogsExporterType
+//   - OTEL_METRICS_EXPORTER: overrides MetricsExporterType; a comma-separated list sets MetricsExporters
+//   - OTEL_LOGS_EXPORTER: overrides LogsExporterType; a comma-separated list sets LogsExporters
 //   - OTEL_PROMETHEUS_PORT: overrides PrometheusPort
 //   - OTEL_LOG_LEVEL: overrides LogLevel (debug, info, warn, error)
 //   - OTEL_LOG_FILE_PATH: overrides LogFilePath
+//   - OTEL_RECOVER_PANICS: overrides RecoverPanics (set to "true" to enable)
+//   - OTEL_SPAN_LINK_COUNT_LIMIT: overrides MaxSpanLinks
+//   - OTEL_SPAN_LEAK_THRESHOLD: overrides SpanLeakThreshold (Go duration, e.g. "5m")
+//   - OTEL_SPAN_METRICS_ENABLED: overrides SpanMetrics (set to "true" to enable)
+//   - OTEL_SPAN_METRICS_ATTRIBUTES: overrides SpanMetricsAttributes (comma-separated)
+//   - OTEL_SPAN_METRICS_MAX_SERIES: overrides SpanMetricsMaxSeries
+//   - OTEL_METRICS_EXEMPLAR_FILTER: overrides ExemplarFilter (trace_based, always_on, always_off)
+//   - OTEL_METRIC_VIEWS_FILE: overrides MetricViewsFile
+//   - OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION: set to
+//     "base2_exponential_bucket_histogram" to enable ExponentialHistograms
+//   - OTEL_METRICS_CARDINALITY_LIMIT: overrides MetricCardinalityLimit
+//   - OTEL_RUNTIME_METRICS_ENABLED: overrides RuntimeMetrics (set to "true" to enable)
+//   - OTEL_PROCESS_METRICS_ENABLED: overrides ProcessMetrics (set to "true" to enable)
+//   - OTEL_METRIC_EXPORT_INTERVAL: overrides MetricExportInterval (milliseconds, or a Go duration)
+//   - OTEL_METRIC_EXPORT_TIMEOUT: overrides MetricExportTimeout (milliseconds, or a Go duration)
+//   - OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE: overrides MetricTemporality
+//     (cumulative, delta, lowmemory)
+//   - OTEL_EXPORTER_PERSISTENT_QUEUE_DIR: overrides PersistentQueueDir
+//   - OTEL_EXPORTER_PERSISTENT_QUEUE_MAX_BYTES: overrides PersistentQueueMaxBytes
+//   - OTEL_BSP_MAX_QUEUE_SIZE, OTEL_BSP_MAX_EXPORT_BATCH_SIZE: override SpanProcessor sizes
+//   - OTEL_BSP_SCHEDULE_DELAY, OTEL_BSP_EXPORT_TIMEOUT: override SpanProcessor durations (milliseconds)
+//   - OTEL_BLRP_MAX_QUEUE_SIZE, OTEL_BLRP_MAX_EXPORT_BATCH_SIZE: override LogProcessor sizes
+//   - OTEL_BLRP_SCHEDULE_DELAY, OTEL_BLRP_EXPORT_TIMEOUT: override LogProcessor durations (milliseconds)
+//   - OTEL_CACHE_KEY_HASH_KEY: overrides CacheKeyHashKey
 //
 // Defaults:
 //   - ServiceName: "unknown-service" (should be overridden)
@@ -198,6 +398,32 @@ type OTelKit struct {
 //   - LogsExporterType: stdout
 //   - PrometheusPort: 9090
 //   - LogLevel: slog.LevelInfo
+//   - RecoverPanics: false
+//   - MaxSpanLinks: 128
+//   - SpanLeakThreshold: 0 (disabled)
+//   - SpanMetrics: false
+//   - SpanMetricsAttributes: none
+//   - SpanMetricsMaxSeries: 1000
+//   - ExemplarFilter: trace_based
+//   - MetricViews: none
+//   - MetricViewsFile: "" (none)
+//   - ExponentialHistograms: false
+//   - ExponentialHistogramMaxSize: 160
+//   - ExponentialHistogramMaxScale: 20
+//   - MetricCardinalityLimit: 2000
+//   - MetricCardinalityLimits: none
+//   - RuntimeMetrics: false
+//   - ProcessMetrics: false
+//   - MetricExportInterval: 15s
+//   - MetricExportTimeout: 30s
+//   - MetricTemporality: cumulative
+//   - MetricTemporalityByKind: none
+//   - TraceExporters, MetricsExporters, LogsExporters: none (single exporter)
+//   - PersistentQueueDir: "" (disabled)
+//   - PersistentQueueMaxBytes: 256 MiB
+//   - SpanProcessor: queue 2048, batch 512, delay 5s, timeout 30s
+//   - LogProcessor: queue 2048, batch 512, delay 1s, timeout 30s
+//   - CacheKeyHashKey: "" (random per process)
 func DefaultConfig() Config {
 	logLevel := slog.LevelInfo
 	switch getEnvOrDefault("OTEL_LOG_LEVEL", "info") {
@@ -209,22 +435,50 @@ func DefaultConfig() Config {
 		logLevel = slog.LevelError
 	}
 	
+	// Comma-separated exporter lists enable several exporters per signal
+	traceExporters := getEnvListOrDefault("OTEL_EXPORTER_TYPE", []string{string(ExporterStdout)})
+	metricsExporters := getEnvListOrDefault("OTEL_METRICS_EXPORTER", []string{string(ExporterPrometheus)})
+	logsExporters := getEnvListOrDefault("OTEL_LOGS_EXPORTER", []string{string(ExporterStdout)})
+	
 	return Config{
-		ServiceName:         getEnvOrDefault("OTEL_SERVICE_NAME", "unknown-service"),
-		ServiceVersion:      getEnvOrDefault("OTEL_SERVICE_VERSION", "1.0.0"),
-		Environment:         getEnvOrDefault("OTEL_ENVIRONMENT", "development"),
-		ExporterType:        ExporterType(getEnvOrDefault("OTEL_EXPORTER_TYPE", string(ExporterStdout))),
-		JaegerURL:           getEnvOrDefault("JAEGER_URL", "http://localhost:14268/api/traces"),
-		OTLPEndpoint:        getEnvOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
-		SampleRate:          0.1, // 10% sampling by default
-		Debug:               getEnvOrDefault("OTEL_DEBUG", "false") == "true",
-		EnableMetrics:       getEnvOrDefault("OTEL_ENABLE_METRICS", "true") == "true",
-		EnableLogs:          getEnvOrDefault("OTEL_ENABLE_LOGS", "true") == "true",
-		MetricsExporterType: ExporterType(getEnvOrDefault("OTEL_METRICS_EXPORTER", string(ExporterPrometheus))),
-		LogsExporterType:    ExporterType(getEnvOrDefault("OTEL_LOGS_EXPORTER", string(ExporterStdout))),
-		PrometheusPort:      9090, // TODO: parse OTEL_PROMETHEUS_PORT as int
-		LogLevel:            logLevel,
-		LogFilePath:         getEnvOrDefault("OTEL_LOG_FILE_PATH", ""),
+		ServiceName:           getEnvOrDefault("OTEL_SERVICE_NAME", "unknown-service"),
+		ServiceVersion:        getEnvOrDefault("OTEL_SERVICE_VERSION", "1.0.0"),
+		Environment:           getEnvOrDefault("OTEL_ENVIRONMENT", "development"),
+		ExporterType:          ExporterType(traceExporters[0]),
+		JaegerURL:             getEnvOrDefault("JAEGER_URL", "http://localhost:14268/api/traces"),
+		OTLPEndpoint:          getEnvOrDefault("OTEL_EXPORTER_OTLP_ENDPOINT", "http://localhost:4318"),
+		SampleRate:            0.1, // 10% sampling by default
+		Debug:                 getEnvOrDefault("OTEL_DEBUG", "false") == "true",
+		EnableMetrics:         getEnvOrDefault("OTEL_ENABLE_METRICS", "true") == "true",
+		EnableLogs:            getEnvOrDefault("OTEL_ENABLE_LOGS", "true") == "true",
+		MetricsExporterType:   ExporterType(metricsExporters[0]),
+		LogsExporterType:      ExporterType(logsExporters[0]),
+		PrometheusPort:        9090, // TODO: parse OTEL_PROMETHEUS_PORT as int
+		LogLevel:              logLevel,
+		LogFilePath:           getEnvOrDefault("OTEL_LOG_FILE_PATH", ""),
+		RecoverPanics:         getEnvOrDefault("OTEL_RECOVER_PANICS", "false") == "true",
+		MaxSpanLinks:          getEnvIntOrDefault("OTEL_SPAN_LINK_COUNT_LIMIT", defaultMaxSpanLinks),
+		SpanLeakThreshold:     getEnvDurationOrDefault("OTEL_SPAN_LEAK_THRESHOLD", 0),
+		SpanMetrics:           getEnvOrDefault("OTEL_SPAN_METRICS_ENABLED", "false") == "true",
+		SpanMetricsAttributes: getEnvListOrDefault("OTEL_SPAN_METRICS_ATTRIBUTES", nil),
+		SpanMetricsMaxSeries:  getEnvIntOrDefault("OTEL_SPAN_METRICS_MAX_SERIES", defaultSpanMetricsMaxSeries),
+		ExemplarFilter:        ExemplarFilter(getEnvOrDefault("OTEL_METRICS_EXEMPLAR_FILTER", string(ExemplarFilterTraceBased))),
+		MetricViewsFile:       getEnvOrDefault("OTEL_METRIC_VIEWS_FILE", ""),
+		ExponentialHistograms: getEnvOrDefault("OTEL_EXPORTER_OTLP_METRICS_DEFAULT_HISTOGRAM_AGGREGATION", "explicit_bucket_histogram") == "base2_exponential_bucket_histogram",
+		MetricCardinalityLimit: getEnvIntOrDefault("OTEL_METRICS_CARDINALITY_LIMIT", defaultMetricCardinalityLimit),
+		RuntimeMetrics:        getEnvOrDefault("OTEL_RUNTIME_METRICS_ENABLED", "false") == "true",
+		ProcessMetrics:        getEnvOrDefault("OTEL_PROCESS_METRICS_ENABLED", "false") == "true",
+		MetricExportInterval:  getEnvMillisOrDefault("OTEL_METRIC_EXPORT_INTERVAL", defaultMetricExportInterval),
+		MetricExportTimeout:   getEnvMillisOrDefault("OTEL_METRIC_EXPORT_TIMEOUT", defaultMetricExportTimeout),
+		MetricTemporality:     MetricTemporality(strings.ToLower(getEnvOrDefault("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", string(MetricTemporalityCumulative)))),
+		TraceExporters:        exporterConfigs(traceExporters),
+		MetricsExporters:      exporterConfigs(metricsExporters),
+		LogsExporters:         exporterConfigs(logsExporters),
+		PersistentQueueDir:    getEnvOrDefault("OTEL_EXPORTER_PERSISTENT_QUEUE_DIR", ""),
+		PersistentQueueMaxBytes: int64(getEnvIntOrDefault("OTEL_EXPORTER_PERSISTENT_QUEUE_MAX_BYTES", defaultPersistentQueueMaxBytes)),
+		SpanProcessor:         batchProcessorConfigFromEnv("OTEL_BSP", defaultSpanScheduleDelay),
+		LogProcessor:          batchProcessorConfigFromEnv("OTEL_BLRP", defaultLogScheduleDelay),
+		CacheKeyHashKey:       getEnvOrDefault("OTEL_CACHE_KEY_HASH_KEY", ""),
 	}
 }
 
@@ -258,6 +512,12 @@ func DefaultConfig() Config {
 //   }
 //   defer kit.Shutdown(context.Background())
 func New(config Config) (*OTelKit, error) {
+	return newKit(config)
+}
+
+// newKit is New with extra metric readers, which collect the kit's metrics
+// alongside the configured metrics exporters (used by tests to read them).
+func newKit(config Config, readers ...sdkmetric.Reader) (*OTelKit, error) {
 	// Create resource
 	res, err := newResource(config)
 	if err != nil {
@@ -265,7 +525,8 @@ func New(config Config) (*OTelKit, error) {
 	}
 
 	kit := &OTelKit{
-		config: config,
+		config:   config,
+		pipeline: &pipelineMetrics{},
 	}
 
 	// Initialize tracing
@@ -275,7 +536,7 @@ func New(config Config) (*OTelKit, error) {
 
 	// Initialize metrics if enabled
 	if config.EnableMetrics {
-		if err := kit.initMetrics(res); err != nil {
+		if err := kit.initMetrics(res, readers...); err != nil {
 			return nil, fmt.Errorf("failed to initialize metrics: %w", err)
 		}
 	}
@@ -287,6 +548,9 @@ func New(config Config) (*OTelKit, error) {
 		}
 	}
 
+	// Log errors of the OpenTelemetry SDK, e.g. failed exports
+	kit.handleSDKErrors()
+
 	if config.Debug {
 		log.Printf("OTelKit initialized: service=%s, version=%s, traces=%s, metrics=%v, logs=%v", 
 			config.ServiceName, config.ServiceVersion, config.ExporterType, config.EnableMetrics, config.EnableLogs)
@@ -334,6 +598,9 @@ func (o *OTelKit) Shutdown(ctx context.Context) error {
 		}
 	}
 
+	// Stop logging SDK errors through this kit
+	o.restoreErrorHandler()
+
 	// Return combined errors if any
 	if len(errs) > 0 {
 		return fmt.Errorf("shutdown errors: %v", errs)
@@ -380,6 +647,7 @@ func (o *OTelKit) StartSpan(ctx context.Context, spanName string, opts ...trace.
 //   - Records any error returned by fn
 //   - Sets span status to error if fn returns an error
 //   - Adds provided attributes to the span
+//   - Records panics in fn with a stack trace (see Config.RecoverPanics)
 //
 // Example:
 //   err := kit.TraceFunction(ctx, "process_order", func(ctx context.Context) error {
@@ -387,14 +655,50 @@ func (o *OTelKit) StartSpan(ctx context.Context, spanName string, opts ...trace.
 //       return processOrder(ctx, orderID)
 //   }, attribute.String("order.id", orderID))
 func (o *OTelKit) TraceFunction(ctx context.Context, functionName string, fn func(ctx context.Context) error, attrs ...attribute.KeyValue) error {
-	ctx, span := o.StartSpan(ctx, functionName)
+	return o.traceWithOptions(ctx, functionName, fn, WithAttributes(attrs...))
+}
+
+// TraceFunctionWithOptions is TraceFunction with span options instead of attributes.
+//
+// Parameters:
+//   - ctx: Context for the operation (may contain parent span)
+//   - functionName: Descriptive name for the span
+//   - fn: The function to execute (receives the span context)
+//   - opts: Span settings (WithSpanKind, WithLinks, WithAttributes, WithStartTime)
+//
+// Returns:
+//   - error: Any error returned by the fn function
+//
+// TraceFunction creates internal spans; use this to pick another kind.
+//
+// Example:
+//   err := kit.TraceFunctionWithOptions(ctx, "grpc.GetUser", fn,
+//       otelkit.WithSpanKind(trace.SpanKindClient),
+//   )
+func (o *OTelKit) TraceFunctionWithOptions(ctx context.Context, functionName string, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	return o.traceWithOptions(ctx, functionName, fn, opts...)
+}
+
+// traceFunction runs fn under a span started with the given options, recording
+// errors and panics on it. A recorded panic is returned as a *PanicError when
+// recoverPanics is set and re-raised otherwise.
+func (o *OTelKit) traceFunction(ctx context.Context, spanName string, fn func(ctx context.Context) error, recoverPanics bool, opts ...trace.SpanStartOption) (err error) {
+	ctx, span := o.StartSpan(ctx, spanName, opts...)
 	defer span.End()
 
-	// Add attributes
-	span.SetAttributes(attrs...)
+	// Capture panics before the span ends
+	defer func() {
+		if rec := recover(); rec != nil {
+			panicErr := o.recordPanic(ctx, span, rec, debug.Stack(), !recoverPanics)
+			if !recoverPanics {
+				panic(rec)
+			}
+			err = panicErr
+		}
+	}()
 
 	// Execute function
-	err := fn(ctx)
+	err = fn(ctx)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -413,6 +717,8 @@ func (o *OTelKit) TraceFunction(ctx context.Context, functionName string, fn fun
 // Returns:
 //   - func(ctx context.Context) error: Wrapped handler function
 //
+// Handler spans are created with SpanKindServer.
+//
 // Example:
 //   wrappedHandler := kit.TraceHTTPHandler("get_user", func(ctx context.Context) error {
 //       // handler logic here
@@ -420,7 +726,9 @@ func (o *OTelKit) TraceFunction(ctx context.Context, functionName string, fn fun
 //   })
 func (o *OTelKit) TraceHTTPHandler(handlerName string, handler func(ctx context.Context) error) func(ctx context.Context) error {
 	return func(ctx context.Context) error {
-		return o.TraceFunction(ctx, fmt.Sprintf("http.%s", handlerName), handler)
+		return o.traceWithOptions(ctx, fmt.Sprintf("http.%s", handlerName), handler,
+			WithSpanKind(trace.SpanKindServer),
+		)
 	}
 }
 
@@ -512,7 +820,9 @@ func (o *OTelKit) GetTracer() trace.Tracer {
 
 // GetMeter returns the underlying OpenTelemetry meter.
 // Use this when you need direct access to OpenTelemetry metrics APIs
-// that aren't wrapped by OTelKit.
+// that aren't wrapped by OTelKit; Counter, Histogram, Gauge, UpDownCounter
+// and the Observable variants cover the common instruments.
+// Synchronous instruments created with it apply Config.MetricCardinalityLimit.
 //
 // Returns:
 //   - metric.Meter: The underlying OpenTelemetry meter instance, or nil if metrics disabled
@@ -622,6 +932,8 @@ func (o *OTelKit) LogWarn(ctx context.Context, msg string, attrs ...slog.Attr) {
 }
 
 // RecordMetric records a business metric
+// All operations share the otelkit_business_operations_total counter, labelled
+// with operation_type; use Counter, Histogram or Gauge for a dedicated metric.
 //
 // Parameters:
 //   - ctx: Context for the metric recording
@@ -702,19 +1014,17 @@ func (o *OTelKit) convertSlogAttr(attr slog.Attr) otellog.KeyValue {
 	}
 }
 
-// IncrementActiveSpans increments the active spans counter (used internally)
-func (o *OTelKit) IncrementActiveSpans(ctx context.Context) {
-	if o.activeSpansGauge != nil {
-		o.activeSpansGauge.Add(ctx, 1)
-	}
-}
+// IncrementActiveSpans is kept for compatibility and does nothing.
+//
+// Deprecated: otelkit_active_spans is maintained by a span processor; spans
+// dropped by the sampler are still recorded so it sees every start and end.
+func (o *OTelKit) IncrementActiveSpans(ctx context.Context) {}
 
-// DecrementActiveSpans decrements the active spans counter (used internally)
-func (o *OTelKit) DecrementActiveSpans(ctx context.Context) {
-	if o.activeSpansGauge != nil {
-		o.activeSpansGauge.Add(ctx, -1)
-	}
-}
+// DecrementActiveSpans is kept for compatibility and does nothing.
+//
+// Deprecated: otelkit_active_spans is maintained by a span processor; spans
+// dropped by the sampler are still recorded so it sees every start and end.
+func (o *OTelKit) DecrementActiveSpans(ctx context.Context) {}
 
 // Helper functions
 
@@ -765,11 +1075,21 @@ func newResource(config Config) (*resource.Resource, error) {
 //   - Invalid URLs are provided
 //   - Unsupported exporter type is specified
 func createExporter(config Config) (sdktrace.SpanExporter, error) {
-	return createTraceExporter(config)
+	return createTraceExporter(config, nil)
+}
+
+// createTraceExporter creates a trace exporter based on configuration; with
+// stats, it counts the exported spans for the otelkit_exporter_* metrics
+func createTraceExporter(config Config, stats *exporterStats) (sdktrace.SpanExporter, error) {
+	exporter, err := newTraceExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	return stats.spanExporter(exporter), nil
 }
 
-// createTraceExporter creates a trace exporter based on configuration
-func createTraceExporter(config Config) (sdktrace.SpanExporter, error) {
+// newTraceExporter creates the trace exporter of config.ExporterType
+func newTraceExporter(config Config) (sdktrace.SpanExporter, error) {
 	switch config.ExporterType {
 	case ExporterJaeger:
 		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(config.JaegerURL)))
@@ -780,12 +1100,26 @@ func createTraceExporter(config Config) (sdktrace.SpanExporter, error) {
 			endpoint = "localhost:4318"
 		}
 		
-		return otlptracehttp.New(
-			context.Background(),
+		opts := []otlptracehttp.Option{
 			otlptracehttp.WithEndpoint(endpoint),
 			otlptracehttp.WithURLPath("/v1/traces"),
 			otlptracehttp.WithInsecure(),
-		)
+		}
+
+		// Buffer exports on disk while the collector is unreachable
+		queue, err := persistentQueueFor(config, "traces", endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if queue == nil {
+			return otlptracehttp.New(context.Background(), opts...)
+		}
+		exporter, err := otlptracehttp.New(context.Background(), append(opts, otlptracehttp.WithHTTPClient(queue.client()))...)
+		if err != nil {
+			queue.Close(context.Background())
+			return nil, err
+		}
+		return queuedSpanExporter{exporter, queue}, nil
 	case ExporterStdout:
 		return stdouttrace.New(stdouttrace.WithPrettyPrint())
 	case ExporterNone:
@@ -795,8 +1129,22 @@ func createTraceExporter(config Config) (sdktrace.SpanExporter, error) {
 	}
 }
 
-// createMetricsExporter creates a metrics exporter based on configuration
-func createMetricsExporter(config Config) (sdkmetric.Reader, error) {
+// createMetricsExporter creates a metrics exporter based on configuration; with
+// stats, push exporters count the exported data points for the
+// otelkit_exporter_* metrics
+func createMetricsExporter(config Config, stats *exporterStats, producers ...sdkmetric.Producer) (sdkmetric.Reader, error) {
+	// Histograms are explicit-bucket unless ExponentialHistograms is set
+	aggregation, err := histogramAggregationSelector(config)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cumulative unless MetricTemporality asks for delta (push exporters only)
+	temporality, err := temporalitySelector(config)
+	if err != nil {
+		return nil, err
+	}
+
 	switch config.MetricsExporterType {
 	case ExporterOTLP:
 		// Construct the metrics endpoint URL
@@ -805,30 +1153,59 @@ func createMetricsExporter(config Config) (sdkmetric.Reader, error) {
 			endpoint = "localhost:4318"
 		}
 		
-		exporter, err := otlpmetrichttp.New(
-			context.Background(),
+		opts := []otlpmetrichttp.Option{
 			otlpmetrichttp.WithEndpoint(endpoint),
 			otlpmetrichttp.WithURLPath("/v1/metrics"),
 			otlpmetrichttp.WithInsecure(),
-		)
+			otlpmetrichttp.WithAggregationSelector(aggregation),
+			otlpmetrichttp.WithTemporalitySelector(temporality),
+		}
+
+		// Buffer exports on disk while the collector is unreachable
+		queue, err := persistentQueueFor(config, "metrics", endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if queue != nil {
+			opts = append(opts, otlpmetrichttp.WithHTTPClient(queue.client()))
+		}
+
+		exporter, err := otlpmetrichttp.New(context.Background(), opts...)
 		if err != nil {
+			if queue != nil {
+				queue.Close(context.Background())
+			}
 			return nil, err
 		}
-		return sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(15*time.Second)), nil
+		if queue != nil {
+			return sdkmetric.NewPeriodicReader(stats.metricExporter(queuedMetricExporter{exporter, queue}), periodicReaderOptions(config, producers)...), nil
+		}
+		return sdkmetric.NewPeriodicReader(stats.metricExporter(exporter), periodicReaderOptions(config, producers)...), nil
 	case ExporterPrometheus:
-		exporter, err := prometheus.New(
+		// Append unit and _total suffixes only to names that lack them
+		opts := []prometheus.Option{
 			prometheus.WithoutTargetInfo(),
-		)
+			prometheus.WithTranslationStrategy(otlptranslator.UnderscoreEscapingWithSuffixes),
+			prometheus.WithAggregationSelector(aggregation),
+		}
+		for _, producer := range producers {
+			opts = append(opts, prometheus.WithProducer(producer))
+		}
+		exporter, err := prometheus.New(opts...)
 		if err != nil {
 			return nil, err
 		}
 		return exporter, nil
 	case ExporterStdout:
-		exporter, err := stdoutmetric.New(stdoutmetric.WithPrettyPrint())
+		exporter, err := stdoutmetric.New(
+			stdoutmetric.WithPrettyPrint(),
+			stdoutmetric.WithAggregationSelector(aggregation),
+			stdoutmetric.WithTemporalitySelector(temporality),
+		)
 		if err != nil {
 			return nil, err
 		}
-		return sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(15*time.Second)), nil
+		return sdkmetric.NewPeriodicReader(stats.metricExporter(exporter), periodicReaderOptions(config, producers)...), nil
 	case ExporterNone:
 		return nil, nil
 	default:
@@ -836,8 +1213,18 @@ func createMetricsExporter(config Config) (sdkmetric.Reader, error) {
 	}
 }
 
-// createLogsExporter creates a logs exporter based on configuration
-func createLogsExporter(config Config) (sdklog.Exporter, error) {
+// createLogsExporter creates a logs exporter based on configuration; with
+// stats, it counts the exported records for the otelkit_exporter_* metrics
+func createLogsExporter(config Config, stats *exporterStats) (sdklog.Exporter, error) {
+	exporter, err := newLogsExporter(config)
+	if err != nil {
+		return nil, err
+	}
+	return stats.logExporter(exporter), nil
+}
+
+// newLogsExporter creates the logs exporter of config.LogsExporterType
+func newLogsExporter(config Config) (sdklog.Exporter, error) {
 	switch config.LogsExporterType {
 	case ExporterOTLP:
 		// Construct the logs endpoint URL
@@ -850,12 +1237,26 @@ func createLogsExporter(config Config) (sdklog.Exporter, error) {
 			log.Printf("Debug: Creating logs exporter with endpoint: %s", endpoint)
 		}
 		
-		return otlploghttp.New(
-			context.Background(),
+		opts := []otlploghttp.Option{
 			otlploghttp.WithEndpoint(endpoint),
 			otlploghttp.WithURLPath("/v1/logs"),
 			otlploghttp.WithInsecure(),
-		)
+		}
+
+		// Buffer exports on disk while the collector is unreachable
+		queue, err := persistentQueueFor(config, "logs", endpoint)
+		if err != nil {
+			return nil, err
+		}
+		if queue == nil {
+			return otlploghttp.New(context.Background(), opts...)
+		}
+		exporter, err := otlploghttp.New(context.Background(), append(opts, otlploghttp.WithHTTPClient(queue.client()))...)
+		if err != nil {
+			queue.Close(context.Background())
+			return nil, err
+		}
+		return queuedLogExporter{exporter, queue}, nil
 	case ExporterStdout:
 		return stdoutlog.New(stdoutlog.WithPrettyPrint())
 	case ExporterNone:
@@ -883,33 +1284,126 @@ func getEnvOrDefault(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvIntOrDefault retrieves an integer environment variable or returns a default value.
+//
+// Parameters:
+//   - key: Environment variable name to look up
+//   - defaultValue: Value to return if the variable is unset, empty or not an integer
+//
+// Returns:
+//   - int: The parsed value, otherwise defaultValue
+func getEnvIntOrDefault(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvDurationOrDefault retrieves a duration environment variable (e.g. "30s", "5m")
+// or returns a default value.
+//
+// Parameters:
+//   - key: Environment variable name to look up
+//   - defaultValue: Value to return if the variable is unset, empty or not a duration
+//
+// Returns:
+//   - time.Duration: The parsed value, otherwise defaultValue
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvMillisOrDefault retrieves a duration environment variable given in
+// milliseconds, as the OpenTelemetry specification defines them (e.g. "5000"),
+// or as a Go duration (e.g. "5s"), or returns a default value.
+//
+// Parameters:
+//   - key: Environment variable name to look up
+//   - defaultValue: Value to return if the variable is unset, empty or invalid
+//
+// Returns:
+//   - time.Duration: The parsed value, otherwise defaultValue
+func getEnvMillisOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if millis, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return time.Duration(millis) * time.Millisecond
+	}
+	return getEnvDurationOrDefault(key, defaultValue)
+}
+
+// getEnvListOrDefault retrieves a comma-separated environment variable or returns a default value.
+//
+// Parameters:
+//   - key: Environment variable name to look up
+//   - defaultValue: Value to return if the variable is unset or empty
+//
+// Returns:
+//   - []string: The trimmed, non-empty items, otherwise defaultValue
+func getEnvListOrDefault(key string, defaultValue []string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	if len(items) == 0 {
+		return defaultValue
+	}
+	return items
+}
+
 // initTracing initializes the tracing components of OTelKit
 func (o *OTelKit) initTracing(res *resource.Resource) error {
-	// Create trace exporter
-	exporter, err := createTraceExporter(o.config)
+	// Create a span processor per trace exporter
+	processors, err := createSpanProcessors(o.config, o.pipeline)
 	if err != nil {
 		return fmt.Errorf("failed to create trace exporter: %w", err)
 	}
 
-	// Create tracer provider
-	var tracerProvider *sdktrace.TracerProvider
-	if exporter != nil {
-		tracerProvider = sdktrace.NewTracerProvider(
-			sdktrace.WithBatcher(exporter),
-			sdktrace.WithResource(res),
-			sdktrace.WithSampler(sdktrace.TraceIDRatioBased(o.config.SampleRate)),
-		)
-	} else {
-		// No-op tracer provider for when exporter is none
-		tracerProvider = sdktrace.NewTracerProvider(
-			sdktrace.WithResource(res),
-			sdktrace.WithSampler(sdktrace.NeverSample()),
-		)
+	// Count live spans for otelkit_active_spans
+	o.activeSpans = newActiveSpanProcessor(o, o.config.SpanLeakThreshold)
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithSpanProcessor(o.activeSpans),
+		sdktrace.WithResource(res),
+	}
+
+	var sampler sdktrace.Sampler = sdktrace.TraceIDRatioBased(o.config.SampleRate)
+	if len(processors) == 0 {
+		// Nothing is exported when exporter is none
+		sampler = sdktrace.NeverSample()
+	}
+	// Leak detection and span metrics need every span, so unsampled spans are
+	// recorded (but not exported) for them; otherwise they stay non-recording
+	spanMetrics := o.config.SpanMetrics && o.config.EnableMetrics
+	if o.config.SpanLeakThreshold > 0 || spanMetrics {
+		sampler = recordOnlySampler{sampler}
+	}
+
+	// Derive RED metrics from spans
+	if spanMetrics {
+		o.spanMetrics = newSpanMetricsProcessor(o, o.config)
+		opts = append(opts, sdktrace.WithSpanProcessor(o.spanMetrics))
+	}
+
+	for _, processor := range processors {
+		opts = append(opts, sdktrace.WithSpanProcessor(processor))
 	}
+	opts = append(opts, sdktrace.WithSampler(sampler))
+
+	// Create tracer provider
+	tracerProvider := sdktrace.NewTracerProvider(opts...)
 
 	// Set global tracer provider
 	otel.SetTracerProvider(tracerProvider)
 
+	// Propagate W3C trace context and baggage across process boundaries
+	propagator := propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	otel.SetTextMapPropagator(propagator)
+	o.propagator = propagator
+
 	// Create tracer
 	tracer := tracerProvider.Tracer(
 		o.config.ServiceName,
@@ -922,27 +1416,47 @@ func (o *OTelKit) initTracing(res *resource.Resource) error {
 	return nil
 }
 
-// initMetrics initializes the metrics components of OTelKit
-func (o *OTelKit) initMetrics(res *resource.Resource) error {
-	// Create metrics exporter
-	exporter, err := createMetricsExporter(o.config)
+// initMetrics initializes the metrics components of OTelKit; extraReaders
+// collect alongside the readers of the configured metrics exporters
+func (o *OTelKit) initMetrics(res *resource.Resource, extraReaders ...sdkmetric.Reader) error {
+	// Attach trace exemplars to measurements
+	filter, err := exemplarFilter(o.config.ExemplarFilter)
+	if err != nil {
+		return err
+	}
+
+	// Customize aggregations, attributes and names
+	views, err := metricViews(o.config)
+	if err != nil {
+		return err
+	}
+
+	// Report the scheduler latency histogram through every reader
+	var producers []sdkmetric.Producer
+	if o.config.RuntimeMetrics {
+		producers = append(producers, newRuntimeProducer(instrumentation.Scope{
+			Name:    o.config.ServiceName,
+			Version: o.config.ServiceVersion,
+		}))
+	}
+
+	// Create a reader per metrics exporter
+	readers, err := createMetricReaders(o.config, o.pipeline, producers...)
 	if err != nil {
 		return fmt.Errorf("failed to create metrics exporter: %w", err)
 	}
+	readers = append(readers, extraReaders...)
 
-	// Create meter provider
-	var meterProvider *sdkmetric.MeterProvider
-	if exporter != nil {
-		meterProvider = sdkmetric.NewMeterProvider(
-			sdkmetric.WithReader(exporter),
-			sdkmetric.WithResource(res),
-		)
-	} else {
-		// No-op meter provider
-		meterProvider = sdkmetric.NewMeterProvider(
-			sdkmetric.WithResource(res),
-		)
+	// Create meter provider; without readers it is a no-op
+	opts := []sdkmetric.Option{
+		sdkmetric.WithResource(res),
+		sdkmetric.WithExemplarFilter(filter),
+		sdkmetric.WithView(sdkViews(views)...),
 	}
+	for _, reader := range readers {
+		opts = append(opts, sdkmetric.WithReader(reader))
+	}
+	meterProvider := sdkmetric.NewMeterProvider(opts...)
 
 	// Set global meter provider
 	otel.SetMeterProvider(meterProvider)
@@ -953,13 +1467,38 @@ func (o *OTelKit) initMetrics(res *resource.Resource) error {
 		metric.WithInstrumentationVersion(o.config.ServiceVersion),
 	)
 
+	// Route series beyond the cardinality limits to otel.metric.overflow=true
+	limiter, err := newCardinalityLimiter(o, o.config, meter, views)
+	if err != nil {
+		return fmt.Errorf("failed to initialize cardinality limiter: %w", err)
+	}
+	meter = limitingMeter{Meter: meter, limiter: limiter}
+
 	// Initialize common metrics instruments
 	if err := o.initMetricsInstruments(meter); err != nil {
 		return fmt.Errorf("failed to initialize metrics instruments: %w", err)
 	}
 
+	// Report on the telemetry pipeline itself
+	if err := o.pipeline.register(meter); err != nil {
+		return fmt.Errorf("failed to initialize pipeline metrics: %w", err)
+	}
+
+	// Report Go runtime and process metrics
+	if o.config.RuntimeMetrics {
+		if _, err := registerRuntimeMetrics(meter); err != nil {
+			return fmt.Errorf("failed to initialize runtime metrics: %w", err)
+		}
+	}
+	if o.config.ProcessMetrics {
+		if _, err := registerProcessMetrics(meter); err != nil {
+			return fmt.Errorf("failed to initialize process metrics: %w", err)
+		}
+	}
+
 	o.meter = meter
 	o.meterProvider = meterProvider
+	o.cardinality = limiter
 
 	return nil
 }
@@ -968,11 +1507,13 @@ func (o *OTelKit) initMetrics(res *resource.Resource) error {
 func (o *OTelKit) initMetricsInstruments(meter metric.Meter) error {
 	var err error
 
-	// HTTP request duration histogram
+	// HTTP request duration histogram; explicit buckets also give each latency
+	// range its own exemplar
 	o.httpRequestDuration, err = meter.Float64Histogram(
 		"http_request_duration_seconds",
 		metric.WithDescription("Duration of HTTP requests in seconds"),
 		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create http_request_duration_seconds histogram: %w", err)
@@ -987,10 +1528,19 @@ func (o *OTelKit) initMetricsInstruments(meter metric.Meter) error {
 		return fmt.Errorf("failed to create http_requests_total counter: %w", err)
 	}
 
-	// Active spans gauge
-	o.activeSpansGauge, err = meter.Int64UpDownCounter(
+	// Active spans gauge, observed from the active span processor
+	_, err = meter.Int64ObservableGauge(
 		"otelkit_active_spans",
 		metric.WithDescription("Number of currently active spans"),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			if o.activeSpans == nil {
+				return nil
+			}
+			for name, count := range o.activeSpans.snapshot() {
+				observer.Observe(count, metric.WithAttributes(attribute.String("span.name", name)))
+			}
+			return nil
+		}),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create otelkit_active_spans gauge: %w", err)
@@ -1005,30 +1555,97 @@ func (o *OTelKit) initMetricsInstruments(meter metric.Meter) error {
 		return fmt.Errorf("failed to create otelkit_business_operations_total counter: %w", err)
 	}
 
+	// Database operation duration histogram
+	o.dbOperationDuration, err = meter.Float64Histogram(
+		"db.client.operation.duration",
+		metric.WithDescription("Duration of database client operations"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create db.client.operation.duration histogram: %w", err)
+	}
+
+	// Cache request counter and latency histogram
+	o.cacheRequestsTotal, err = meter.Int64Counter(
+		"cache_requests_total",
+		metric.WithDescription("Total number of cache requests by result"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create cache_requests_total counter: %w", err)
+	}
+	o.cacheRequestDuration, err = meter.Float64Histogram(
+		"cache_request_duration_seconds",
+		metric.WithDescription("Duration of cache requests in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.0001, 0.0005, 0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create cache_request_duration_seconds histogram: %w", err)
+	}
+
+	// Batch item counters and duration histogram
+	o.batchItemsProcessed, err = meter.Int64Counter(
+		"batch_items_processed_total",
+		metric.WithDescription("Total number of batch items processed"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create batch_items_processed_total counter: %w", err)
+	}
+	o.batchItemsFailed, err = meter.Int64Counter(
+		"batch_items_failed_total",
+		metric.WithDescription("Total number of batch items that failed"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create batch_items_failed_total counter: %w", err)
+	}
+	o.batchDuration, err = meter.Float64Histogram(
+		"batch_duration_seconds",
+		metric.WithDescription("Duration of batch operations in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create batch_duration_seconds histogram: %w", err)
+	}
+
+	// Span-derived call counter and duration histogram (recorded when Config.SpanMetrics is set)
+	o.spanCallsTotal, err = meter.Int64Counter(
+		"calls_total",
+		metric.WithDescription("Total number of ended spans by name, kind and status"),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create calls_total counter: %w", err)
+	}
+	o.spanDuration, err = meter.Float64Histogram(
+		"duration",
+		metric.WithDescription("Duration of spans in seconds"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create duration histogram: %w", err)
+	}
+
 	return nil
 }
 
 // initLogging initializes the logging components of OTelKit
 func (o *OTelKit) initLogging(res *resource.Resource) error {
-	// Create logs exporter
-	exporter, err := createLogsExporter(o.config)
+	// Create a log processor per logs exporter
+	processors, err := createLogProcessors(o.config, o.pipeline)
 	if err != nil {
 		return fmt.Errorf("failed to create logs exporter: %w", err)
 	}
 
-	// Create logger provider
-	var loggerProvider *sdklog.LoggerProvider
-	if exporter != nil {
-		loggerProvider = sdklog.NewLoggerProvider(
-			sdklog.WithProcessor(sdklog.NewBatchProcessor(exporter)),
-			sdklog.WithResource(res),
-		)
-	} else {
-		// No-op logger provider
-		loggerProvider = sdklog.NewLoggerProvider(
-			sdklog.WithResource(res),
-		)
+	// Create logger provider; without processors it is a no-op
+	opts := []sdklog.LoggerProviderOption{
+		sdklog.WithResource(res),
+	}
+	for _, processor := range processors {
+		opts = append(opts, sdklog.WithProcessor(processor))
 	}
+	loggerProvider := sdklog.NewLoggerProvider(opts...)
 
 	// Set global logger provider
 	// TODO: Set when available in SDK
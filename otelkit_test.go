@@ -2,10 +2,16 @@ package otelkit
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestOTelKitBasicFunctionality(t *testing.T) {
@@ -150,6 +156,37 @@ func TestOTelKitConfiguration(t *testing.T) {
 	})
 }
 
+func TestSpanKinds(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "kind-test"})
+	ctx := context.Background()
+	noop := func(ctx context.Context) error { return nil }
+
+	kit.TraceFunction(ctx, "internal", noop)
+	kit.DatabaseOperation(ctx, "SELECT", "users", noop)
+	kit.CacheOperation(ctx, "get", "user:1", noop)
+	kit.ExternalServiceCall(ctx, "payments", "charge", noop)
+	kit.ExternalServiceCallWithOptions(ctx, "peer", "sync", noop, WithSpanKind(trace.SpanKindProducer))
+	kit.HTTPMiddleware(http.NotFoundHandler()).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := map[string]trace.SpanKind{
+		"internal":                 trace.SpanKindInternal,
+		"db.SELECT":                trace.SpanKindClient,
+		"cache.get":                trace.SpanKindClient,
+		"external.payments.charge": trace.SpanKindClient,
+		"external.peer.sync":       trace.SpanKindProducer,
+		"GET /":                    trace.SpanKindServer,
+	}
+	for _, span := range recorder.Ended() {
+		if kind, ok := want[span.Name()]; ok && span.SpanKind() != kind {
+			t.Errorf("Span %s: expected kind %v, got %v", span.Name(), kind, span.SpanKind())
+		}
+		delete(want, span.Name())
+	}
+	if len(want) > 0 {
+		t.Errorf("Missing spans: %v", want)
+	}
+}
+
 func BenchmarkOTelKitOverhead(b *testing.B) {
 	config := DefaultConfig()
 	config.ExporterType = ExporterNone
@@ -185,3 +222,59 @@ func BenchmarkOTelKitOverhead(b *testing.B) {
 		}
 	})
 }
+
+// newMetricsKit returns a kit initialized by New from config, with metrics
+// enabled and no exporters, and a reader collecting its metrics with the
+// histogram aggregation of config.
+func newMetricsKit(t *testing.T, config Config) (*OTelKit, *sdkmetric.ManualReader) {
+	t.Helper()
+
+	selector, err := histogramAggregationSelector(config)
+	if err != nil {
+		t.Fatalf("histogramAggregationSelector failed: %v", err)
+	}
+	reader := sdkmetric.NewManualReader(sdkmetric.WithAggregationSelector(selector))
+
+	config.ExporterType = ExporterNone
+	config.MetricsExporterType = ExporterNone
+	config.EnableMetrics = true
+	kit, err := newKit(config, reader)
+	if err != nil {
+		t.Fatalf("Failed to initialize OTelKit: %v", err)
+	}
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		kit.Shutdown(ctx)
+	})
+
+	return kit, reader
+}
+
+// newRecordingKit creates an OTelKit whose spans are captured in memory so tests
+// can inspect names, kinds, attributes, events and status.
+func newRecordingKit(t *testing.T, config Config) (*OTelKit, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	config.ExporterType = ExporterNone
+	kit, err := New(config)
+	if err != nil {
+		t.Fatalf("Failed to initialize OTelKit: %v", err)
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	kit.tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithSpanProcessor(kit.activeSpans),
+		sdktrace.WithSpanProcessor(recorder),
+	)
+	kit.tracer = kit.tracerProvider.Tracer("otelkit-test")
+
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		kit.Shutdown(ctx)
+	})
+
+	return kit, recorder
+}
@@ -0,0 +1,71 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// PanicError wraps a value recovered from a panic together with the stack trace
+// captured at the point of recovery.
+//
+// TraceFunction returns a *PanicError instead of re-panicking when
+// Config.RecoverPanics is enabled.
+type PanicError struct {
+	// Value is the value passed to panic()
+	Value any
+
+	// Stack is the goroutine stack trace captured when the panic was recovered
+	Stack []byte
+}
+
+// Error implements the error interface.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, so errors.Is and errors.As
+// can see through the panic.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// recordPanic records a recovered panic on the span and logs it with trace correlation.
+//
+// Parameters:
+//   - ctx: Context containing the span (used for log correlation)
+//   - span: The span the panic happened under
+//   - rec: The value returned by recover()
+//   - stack: The stack trace captured at recovery time
+//   - escaped: Whether the panic is re-raised after recording (exception.escaped)
+//
+// Returns:
+//   - *PanicError: The panic wrapped as an error
+//
+// The span receives an "exception" event with exception.type, exception.message
+// and exception.stacktrace, and its status is set to error.
+func (o *OTelKit) recordPanic(ctx context.Context, span trace.Span, rec any, stack []byte, escaped bool) *PanicError {
+	panicErr := &PanicError{Value: rec, Stack: stack}
+
+	span.AddEvent("exception", trace.WithAttributes(
+		semconv.ExceptionType(fmt.Sprintf("%T", rec)),
+		semconv.ExceptionMessage(fmt.Sprint(rec)),
+		semconv.ExceptionStacktrace(string(stack)),
+		attribute.Bool("exception.escaped", escaped),
+	))
+	span.SetStatus(codes.Error, panicErr.Error())
+
+	o.LogError(ctx, "Panic recovered", panicErr,
+		slog.String("stacktrace", string(stack)),
+	)
+
+	return panicErr
+}
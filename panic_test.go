@@ -0,0 +1,121 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+func TestPanicRecovery(t *testing.T) {
+	config := Config{ServiceName: "panic-test"}
+
+	t.Run("TraceFunctionRepanics", func(t *testing.T) {
+		kit, recorder := newRecordingKit(t, config)
+
+		func() {
+			defer func() {
+				if rec := recover(); rec != "boom" {
+					t.Errorf("Expected re-panic with 'boom', got %v", rec)
+				}
+			}()
+			kit.TraceFunction(context.Background(), "panicky", func(ctx context.Context) error {
+				panic("boom")
+			})
+		}()
+
+		assertPanicSpan(t, recorder.Ended())
+	})
+
+	t.Run("TraceFunctionRecovers", func(t *testing.T) {
+		config := config
+		config.RecoverPanics = true
+		kit, recorder := newRecordingKit(t, config)
+
+		sentinel := errors.New("sentinel")
+		err := kit.TraceFunction(context.Background(), "panicky", func(ctx context.Context) error {
+			panic(sentinel)
+		})
+
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Fatalf("Expected *PanicError, got %v", err)
+		}
+		if !errors.Is(err, sentinel) {
+			t.Error("PanicError should unwrap to the panic value")
+		}
+		if len(panicErr.Stack) == 0 {
+			t.Error("PanicError should carry a stack trace")
+		}
+
+		assertPanicSpan(t, recorder.Ended())
+	})
+
+	t.Run("HTTPMiddlewareRecovers", func(t *testing.T) {
+		config := config
+		config.RecoverPanics = true
+		kit, recorder := newRecordingKit(t, config)
+
+		handler := kit.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("handler exploded")
+		}))
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+		if rec.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", rec.Code)
+		}
+		assertPanicSpan(t, recorder.Ended())
+	})
+
+	t.Run("HTTPMiddlewareRepanics", func(t *testing.T) {
+		kit, recorder := newRecordingKit(t, config)
+
+		handler := kit.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic("handler exploded")
+		}))
+
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("Expected middleware to re-panic")
+				}
+			}()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+		}()
+
+		assertPanicSpan(t, recorder.Ended())
+	})
+}
+
+// assertPanicSpan checks that exactly one span ended with an error status and an
+// exception event carrying a stack trace.
+func assertPanicSpan(t *testing.T, spans []sdktrace.ReadOnlySpan) {
+	t.Helper()
+
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status, got %v", span.Status().Code)
+	}
+
+	for _, event := range span.Events() {
+		if event.Name != "exception" {
+			continue
+		}
+		for _, attr := range event.Attributes {
+			if attr.Key == "exception.stacktrace" && strings.Contains(attr.Value.AsString(), "goroutine") {
+				return
+			}
+		}
+	}
+	t.Error("Expected exception event with exception.stacktrace")
+}
@@ -0,0 +1,718 @@
+package otelkit
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultPersistentQueueMaxBytes is used when Config.PersistentQueueMaxBytes is 0.
+const defaultPersistentQueueMaxBytes = 256 << 20
+
+// Segment files: a queued export request is stored as
+//
+//	magic "OKQ1" | uint32 header length | header JSON | uint32 body length | body | uint32 CRC-32
+//
+// written to a temporary file, synced and renamed, so a segment is either
+// complete or absent; the checksum catches later corruption.
+const (
+	segmentMagic  = "OKQ1"
+	segmentSuffix = ".seg"
+	tempSuffix    = ".tmp"
+	lockFile      = "lock"
+)
+
+// segmentHeaderKeys are the request headers stored in a segment. The others,
+// such as Authorization or API keys from OTEL_EXPORTER_OTLP_HEADERS, are never
+// written to disk; the exporter's current headers are applied when sending.
+var segmentHeaderKeys = []string{"Content-Type", "Content-Encoding"}
+
+// segmentHeader describes the HTTP request stored in a segment and the number
+// of spans, data points or records it carries.
+type segmentHeader struct {
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Items  int64       `json:"items,omitempty"`
+}
+
+// persistentQueue is an http.RoundTripper for the OTLP/HTTP exporters that
+// stores every export request in a segment file and answers it at once; a
+// background sender delivers the segments in order, retrying with exponential
+// backoff while the collector is unreachable. Segments left over from a
+// previous run are replayed on start. When the segments exceed maxBytes the
+// oldest are dropped. The directory is locked, so only one queue uses it.
+//
+// Fields:
+//   - dir: Directory of the segment files
+//   - maxBytes: Disk usage limit of the segments
+//   - initialBackoff, maxBackoff: Retry delays after a failed delivery
+//   - transport: Delivers the stored requests
+//   - lock: The locked lock file of dir
+//   - headers: Exporter headers applied to every delivery (kept in memory only)
+//   - segments: The queued segment files, oldest first
+//   - total: Combined size of the segment files
+//   - items: Combined items of the segments
+//   - stats: Counts deliveries and drops for the otelkit_* pipeline metrics (may be nil)
+type persistentQueue struct {
+	dir            string
+	maxBytes       int64
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	attemptTimeout time.Duration
+	transport      http.RoundTripper
+	lock           *os.File
+
+	mu       sync.Mutex
+	headers  http.Header
+	segments []queuedSegment
+	total    int64
+	items    int64
+	nextSeq  uint64
+	closing  bool
+	stats    *exporterStats
+
+	wake      chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+	ctx       context.Context
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// queuedSegment is a segment file waiting for delivery.
+type queuedSegment struct {
+	name  string
+	size  int64
+	items int64
+}
+
+// newPersistentQueue opens the queue in dir and starts delivering its segments.
+func newPersistentQueue(dir string, maxBytes int64) (*persistentQueue, error) {
+	q, err := openPersistentQueue(dir, maxBytes)
+	if err != nil {
+		return nil, err
+	}
+	q.start()
+	return q, nil
+}
+
+// openPersistentQueue opens (creating if needed) the queue in dir, keeping the
+// segments of a previous run and removing incomplete writes.
+func openPersistentQueue(dir string, maxBytes int64) (*persistentQueue, error) {
+	if maxBytes <= 0 {
+		maxBytes = defaultPersistentQueueMaxBytes
+	}
+	// Segments hold telemetry payloads, so only the owner may read them,
+	// including in directories created by an earlier version
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create persistent queue %s: %w", dir, err)
+	}
+	if err := os.Chmod(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to restrict persistent queue %s: %w", dir, err)
+	}
+	lock, err := lockQueueDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock persistent queue %s: %w", dir, err)
+	}
+
+	q := &persistentQueue{
+		dir:            dir,
+		maxBytes:       maxBytes,
+		initialBackoff: time.Second,
+		maxBackoff:     time.Minute,
+		attemptTimeout: 30 * time.Second,
+		transport:      http.DefaultTransport,
+		lock:           lock,
+		headers:        make(http.Header),
+		wake:           make(chan struct{}, 1),
+		closed:         make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	if err := q.load(); err != nil {
+		unlockQueueDir(lock)
+		return nil, err
+	}
+	return q, nil
+}
+
+// start starts the sender.
+func (q *persistentQueue) start() {
+	go q.run()
+	q.notify()
+}
+
+// load scans the queue directory for segments of a previous run.
+func (q *persistentQueue) load() error {
+	entries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read persistent queue %s: %w", q.dir, err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasSuffix(name, tempSuffix):
+			// Interrupted write
+			os.Remove(filepath.Join(q.dir, name))
+		case strings.HasSuffix(name, segmentSuffix):
+			seq, err := strconv.ParseUint(strings.TrimSuffix(name, segmentSuffix), 10, 64)
+			info, statErr := entry.Info()
+			if err != nil || statErr != nil {
+				continue
+			}
+			// Unreadable headers are reported when the segment is delivered
+			header, _ := readSegmentHeader(filepath.Join(q.dir, name))
+			q.segments = append(q.segments, queuedSegment{name: name, size: info.Size(), items: header.Items})
+			q.total += info.Size()
+			q.items += header.Items
+			q.nextSeq = max(q.nextSeq, seq+1)
+		}
+	}
+	// Zero-padded sequence numbers sort by age
+	slices.SortFunc(q.segments, func(a, b queuedSegment) int { return strings.Compare(a.name, b.name) })
+	return nil
+}
+
+// client returns an HTTP client whose requests are queued.
+func (q *persistentQueue) client() *http.Client {
+	return &http.Client{Transport: q}
+}
+
+// track makes the queue report its deliveries, drops and depth to stats.
+func (q *persistentQueue) track(stats *exporterStats) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.stats = stats
+	stats.persistent.Store(true)
+	stats.persisted.Store(q.items)
+}
+
+// adjustItems adds delta to the queued items. Callers hold q.mu.
+func (q *persistentQueue) adjustItems(delta int64) {
+	q.items += delta
+	if q.stats != nil {
+		q.stats.persisted.Store(q.items)
+	}
+}
+
+// exportItemsKey is the context key of the item count of an export request.
+type exportItemsKey struct{}
+
+// withExportItems returns ctx carrying the number of items exported with it,
+// which the persistent queue stores with the request.
+func withExportItems(ctx context.Context, n int) context.Context {
+	return context.WithValue(ctx, exportItemsKey{}, int64(n))
+}
+
+// RoundTrip implements http.RoundTripper: it stores req and reports success.
+func (q *persistentQueue) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Remember the exporter's other headers for delivery instead of storing them
+	items, _ := req.Context().Value(exportItemsKey{}).(int64)
+	header := segmentHeader{URL: req.URL.String(), Header: make(http.Header), Items: items}
+	exporterHeaders := req.Header.Clone()
+	for _, key := range segmentHeaderKeys {
+		if values := exporterHeaders.Values(key); len(values) > 0 {
+			header.Header[key] = values
+		}
+		exporterHeaders.Del(key)
+	}
+	q.mu.Lock()
+	q.headers = exporterHeaders
+	q.mu.Unlock()
+
+	if err := q.enqueue(header, body); err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     make(http.Header),
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
+
+// enqueue writes a segment and wakes the sender, dropping the oldest segments
+// when the queue would exceed maxBytes.
+func (q *persistentQueue) enqueue(header segmentHeader, body []byte) error {
+	data, err := encodeSegment(header, body)
+	if err != nil {
+		return err
+	}
+	size := int64(len(data))
+	if size > q.maxBytes {
+		return fmt.Errorf("persistent queue %s: export request of %d bytes exceeds the queue size of %d bytes", q.dir, size, q.maxBytes)
+	}
+
+	q.mu.Lock()
+	if q.closing {
+		q.mu.Unlock()
+		return fmt.Errorf("persistent queue %s is closed", q.dir)
+	}
+	name := fmt.Sprintf("%020d%s", q.nextSeq, segmentSuffix)
+	q.nextSeq++
+	var dropped []queuedSegment
+	var droppedItems int64
+	for q.total+size > q.maxBytes && len(q.segments) > 0 {
+		dropped = append(dropped, q.segments[0])
+		droppedItems += q.segments[0].items
+		q.total -= q.segments[0].size
+		q.adjustItems(-q.segments[0].items)
+		q.segments = q.segments[1:]
+	}
+	stats := q.stats
+	q.mu.Unlock()
+
+	for _, segment := range dropped {
+		os.Remove(filepath.Join(q.dir, segment.name))
+	}
+	if len(dropped) > 0 {
+		if stats != nil {
+			stats.dropped.Add(droppedItems)
+		}
+		otel.Handle(fmt.Errorf("persistent queue %s is full: dropped the %d oldest export requests", q.dir, len(dropped)))
+	}
+
+	if err := writeFileAtomic(filepath.Join(q.dir, name), data); err != nil {
+		return fmt.Errorf("persistent queue %s: %w", q.dir, err)
+	}
+
+	q.mu.Lock()
+	q.segments = append(q.segments, queuedSegment{name: name, size: size, items: header.Items})
+	q.total += size
+	q.adjustItems(header.Items)
+	q.mu.Unlock()
+	q.notify()
+	return nil
+}
+
+// notify wakes the sender without blocking.
+func (q *persistentQueue) notify() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run delivers segments oldest first until the queue is closed.
+func (q *persistentQueue) run() {
+	defer close(q.done)
+
+	backoff := time.Duration(0)
+	for {
+		q.mu.Lock()
+		var segment queuedSegment
+		closing := q.closing
+		if len(q.segments) > 0 {
+			segment = q.segments[0]
+		}
+		q.mu.Unlock()
+
+		if segment.name == "" {
+			if closing {
+				return
+			}
+			select {
+			case <-q.wake:
+			case <-q.ctx.Done():
+				return
+			}
+			continue
+		}
+
+		retryAfter, err := q.deliver(segment)
+		if err == nil {
+			backoff = 0
+			continue
+		}
+		if closing {
+			// Keep the rest for the next run
+			return
+		}
+
+		if backoff == 0 {
+			backoff = q.initialBackoff
+		} else {
+			backoff = min(2*backoff, q.maxBackoff)
+		}
+		delay := max(retryAfter, backoff/2+rand.N(backoff/2+1))
+		otel.Handle(fmt.Errorf("persistent queue %s: delivery failed, retrying in %s: %w", q.dir, delay.Round(time.Millisecond), err))
+
+		// Close cuts the wait short for a last delivery attempt
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-q.closed:
+			timer.Stop()
+		case <-q.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// deliver sends one segment and removes it once it was delivered or rejected
+// for good. A retryable failure returns an error and the delay the collector
+// asked for, if any. Every attempt is counted in the pipeline metrics.
+func (q *persistentQueue) deliver(segment queuedSegment) (time.Duration, error) {
+	name := segment.name
+	path := filepath.Join(q.dir, name)
+	header, body, err := readSegment(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// Dropped while the queue was full
+		q.remove(name)
+		return 0, nil
+	}
+	if err != nil {
+		otel.Handle(fmt.Errorf("persistent queue %s: dropping unreadable segment %s: %w", q.dir, name, err))
+		q.drop(segment)
+		return 0, nil
+	}
+
+	ctx, cancel := context.WithTimeout(q.ctx, q.attemptTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, header.URL, bytes.NewReader(body))
+	if err != nil {
+		otel.Handle(fmt.Errorf("persistent queue %s: dropping segment %s: %w", q.dir, name, err))
+		q.drop(segment)
+		return 0, nil
+	}
+	q.mu.Lock()
+	for key, values := range q.headers {
+		req.Header[key] = values
+	}
+	q.mu.Unlock()
+	for _, key := range segmentHeaderKeys {
+		if values := header.Header.Values(key); len(values) > 0 {
+			req.Header[key] = values
+		}
+	}
+
+	start := time.Now()
+	resp, err := q.transport.RoundTrip(req)
+	if err != nil {
+		q.attempted(segment, start, err)
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		q.attempted(segment, start, nil)
+		q.remove(name)
+		return 0, nil
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusBadGateway,
+		resp.StatusCode == http.StatusServiceUnavailable, resp.StatusCode == http.StatusGatewayTimeout:
+		err := fmt.Errorf("collector responded %s", resp.Status)
+		q.attempted(segment, start, err)
+		return retryAfter(resp.Header), err
+	default:
+		err := fmt.Errorf("collector rejected segment %s: %s", name, resp.Status)
+		q.attempted(segment, start, err)
+		otel.Handle(fmt.Errorf("persistent queue %s: %w", q.dir, err))
+		q.remove(name)
+		return 0, nil
+	}
+}
+
+// attempted records a delivery attempt of segment that started at start.
+func (q *persistentQueue) attempted(segment queuedSegment, start time.Time, err error) {
+	q.mu.Lock()
+	stats := q.stats
+	q.mu.Unlock()
+	if stats != nil {
+		stats.record(q.ctx, segment.items, time.Since(start), err)
+	}
+}
+
+// drop removes a segment that cannot be delivered and counts its items as dropped.
+func (q *persistentQueue) drop(segment queuedSegment) {
+	q.mu.Lock()
+	stats := q.stats
+	q.mu.Unlock()
+	if stats != nil {
+		stats.dropped.Add(segment.items)
+	}
+	q.remove(segment.name)
+}
+
+// remove deletes a delivered segment.
+func (q *persistentQueue) remove(name string) {
+	q.mu.Lock()
+	if i := slices.IndexFunc(q.segments, func(s queuedSegment) bool { return s.name == name }); i >= 0 {
+		q.total -= q.segments[i].size
+		q.adjustItems(-q.segments[i].items)
+		q.segments = slices.Delete(q.segments, i, i+1)
+	}
+	q.mu.Unlock()
+	os.Remove(filepath.Join(q.dir, name))
+}
+
+// pending returns the number of queued export requests.
+func (q *persistentQueue) pending() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.segments)
+}
+
+// Close stops accepting requests and tries to deliver the queued ones once
+// until ctx is done; undelivered segments stay on disk for the next run.
+// The sender stops waiting out a retry delay, so a collector outage does not
+// hold Close until ctx is done.
+func (q *persistentQueue) Close(ctx context.Context) error {
+	q.closeOnce.Do(func() {
+		q.mu.Lock()
+		q.closing = true
+		q.mu.Unlock()
+		close(q.closed)
+		q.notify()
+	})
+
+	var err error
+	select {
+	case <-q.done:
+	case <-ctx.Done():
+		q.cancel()
+		<-q.done
+		err = ctx.Err()
+	}
+	q.mu.Lock()
+	if q.lock != nil {
+		unlockQueueDir(q.lock)
+		q.lock = nil
+	}
+	q.mu.Unlock()
+	return err
+}
+
+// retryAfter parses a Retry-After header given in seconds.
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// encodeSegment serializes a request into the segment format.
+func encodeSegment(header segmentHeader, body []byte) ([]byte, error) {
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, 0, len(segmentMagic)+12+len(headerJSON)+len(body))
+	buf = append(buf, segmentMagic...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(headerJSON)))
+	buf = append(buf, headerJSON...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(body)))
+	buf = append(buf, body...)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(buf)), nil
+}
+
+// readSegment reads and verifies a segment file.
+func readSegment(path string) (segmentHeader, []byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return segmentHeader{}, nil, err
+	}
+	return decodeSegment(data)
+}
+
+// readSegmentHeader reads the header of a segment file without reading the
+// body or verifying the checksum.
+func readSegmentHeader(path string) (segmentHeader, error) {
+	var header segmentHeader
+	f, err := os.Open(path)
+	if err != nil {
+		return header, err
+	}
+	defer f.Close()
+
+	prefix := make([]byte, len(segmentMagic)+4)
+	if _, err := io.ReadFull(f, prefix); err != nil || string(prefix[:len(segmentMagic)]) != segmentMagic {
+		return header, errors.New("not a segment")
+	}
+	headerLen := binary.BigEndian.Uint32(prefix[len(segmentMagic):])
+	if headerLen > 1<<20 {
+		return header, errors.New("invalid header length")
+	}
+	headerJSON := make([]byte, headerLen)
+	if _, err := io.ReadFull(f, headerJSON); err != nil {
+		return header, errors.New("truncated header")
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return header, fmt.Errorf("invalid header: %w", err)
+	}
+	return header, nil
+}
+
+// decodeSegment parses and verifies the segment format.
+func decodeSegment(data []byte) (segmentHeader, []byte, error) {
+	var header segmentHeader
+	if len(data) < len(segmentMagic)+12 || string(data[:len(segmentMagic)]) != segmentMagic {
+		return header, nil, errors.New("not a segment")
+	}
+	content, checksum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(content) != checksum {
+		return header, nil, errors.New("checksum mismatch")
+	}
+
+	rest := content[len(segmentMagic):]
+	headerLen := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if uint64(headerLen)+4 > uint64(len(rest)) {
+		return header, nil, errors.New("truncated header")
+	}
+	if err := json.Unmarshal(rest[:headerLen], &header); err != nil {
+		return header, nil, fmt.Errorf("invalid header: %w", err)
+	}
+	rest = rest[headerLen:]
+	bodyLen := binary.BigEndian.Uint32(rest)
+	rest = rest[4:]
+	if uint64(bodyLen) != uint64(len(rest)) {
+		return header, nil, errors.New("truncated body")
+	}
+	return header, rest, nil
+}
+
+// writeFileAtomic writes data to a temporary file, syncs it and renames it to path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp := path + tempSuffix
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	// Persist the rename
+	if dir, err := os.Open(filepath.Dir(path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// persistentQueueFor returns the queue of one OTLP exporter, or nil when
+// Config.PersistentQueueDir is not set. Each signal and endpoint gets its own
+// subdirectory, e.g. "traces-otel-collector_4318".
+func persistentQueueFor(config Config, signal, endpoint string) (*persistentQueue, error) {
+	if config.PersistentQueueDir == "" {
+		return nil, nil
+	}
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://"))
+	q, err := newPersistentQueue(filepath.Join(config.PersistentQueueDir, signal+"-"+name), config.PersistentQueueMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	// Segments replayed before the exporter's first request need its headers too
+	q.mu.Lock()
+	q.headers = otlpHeadersFromEnv(signal)
+	q.mu.Unlock()
+	return q, nil
+}
+
+// otlpHeadersFromEnv returns the headers the OTLP/HTTP exporter of signal reads
+// from OTEL_EXPORTER_OTLP_HEADERS and OTEL_EXPORTER_OTLP_<SIGNAL>_HEADERS, as
+// comma-separated key=value pairs with URL-encoded values.
+func otlpHeadersFromEnv(signal string) http.Header {
+	headers := make(http.Header)
+	for _, key := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_" + strings.ToUpper(signal) + "_HEADERS"} {
+		for _, pair := range strings.Split(os.Getenv(key), ",") {
+			name, value, found := strings.Cut(pair, "=")
+			if !found || strings.TrimSpace(name) == "" {
+				continue
+			}
+			if decoded, err := url.PathUnescape(value); err == nil {
+				headers.Set(strings.TrimSpace(name), strings.TrimSpace(decoded))
+			}
+		}
+	}
+	return headers
+}
+
+// queuedSpanExporter closes its persistent queue when the exporter shuts down.
+type queuedSpanExporter struct {
+	sdktrace.SpanExporter
+	queue *persistentQueue
+}
+
+// Shutdown implements sdktrace.SpanExporter.
+func (e queuedSpanExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.queue.Close(ctx))
+}
+
+// queuedMetricExporter closes its persistent queue when the exporter shuts down.
+type queuedMetricExporter struct {
+	sdkmetric.Exporter
+	queue *persistentQueue
+}
+
+// Shutdown implements sdkmetric.Exporter.
+func (e queuedMetricExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.queue.Close(ctx))
+}
+
+// queuedLogExporter closes its persistent queue when the exporter shuts down.
+type queuedLogExporter struct {
+	sdklog.Exporter
+	queue *persistentQueue
+}
+
+// Shutdown implements sdklog.Exporter.
+func (e queuedLogExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.Exporter.Shutdown(ctx), e.queue.Close(ctx))
+}
@@ -0,0 +1,37 @@
+//go:build !unix
+
+package otelkit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// lockedQueueDirs holds the queue directories opened by this process.
+var lockedQueueDirs sync.Map
+
+// lockQueueDir refuses a directory already opened by a queue of this process.
+// Without flock it cannot see queues of other processes.
+func lockQueueDir(dir string) (*os.File, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, loaded := lockedQueueDirs.LoadOrStore(abs, struct{}{}); loaded {
+		return nil, errors.New("directory is used by another persistent queue")
+	}
+	f, err := os.OpenFile(filepath.Join(abs, lockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		lockedQueueDirs.Delete(abs)
+		return nil, err
+	}
+	return f, nil
+}
+
+// unlockQueueDir releases a lock taken by lockQueueDir.
+func unlockQueueDir(f *os.File) {
+	lockedQueueDirs.Delete(filepath.Dir(f.Name()))
+	f.Close()
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package otelkit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// lockQueueDir takes an exclusive flock on the lock file of dir, failing if
+// another queue, in this process or another one, holds it.
+func lockQueueDir(dir string) (*os.File, error) {
+	f, err := os.OpenFile(filepath.Join(dir, lockFile), os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errors.New("directory is used by another persistent queue")
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// unlockQueueDir releases a lock taken by lockQueueDir.
+func unlockQueueDir(f *os.File) {
+	syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	f.Close()
+}
@@ -0,0 +1,345 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// collectorStub is a local OTLP/HTTP endpoint that can simulate an outage by
+// answering with an error status.
+type collectorStub struct {
+	*httptest.Server
+	status atomic.Int32
+
+	mu       sync.Mutex
+	attempts int
+	bodies   []string
+	types    []string
+	auth     []string
+}
+
+func newCollectorStub(t *testing.T, status int) *collectorStub {
+	t.Helper()
+	stub := &collectorStub{}
+	stub.status.Store(int32(status))
+	stub.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		status := int(stub.status.Load())
+
+		stub.mu.Lock()
+		stub.attempts++
+		if status == http.StatusOK {
+			stub.bodies = append(stub.bodies, string(body))
+			stub.types = append(stub.types, r.Header.Get("Content-Type"))
+			stub.auth = append(stub.auth, r.Header.Get("Authorization"))
+		}
+		stub.mu.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(stub.Close)
+	return stub
+}
+
+func (s *collectorStub) received() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.bodies)
+}
+
+func (s *collectorStub) attemptCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.attempts
+}
+
+// openTestQueue opens a queue in dir with short retry delays and starts it.
+func openTestQueue(t *testing.T, dir string, maxBytes int64) *persistentQueue {
+	t.Helper()
+	q, err := openPersistentQueue(dir, maxBytes)
+	if err != nil {
+		t.Fatalf("Failed to open queue: %v", err)
+	}
+	q.initialBackoff = 5 * time.Millisecond
+	q.maxBackoff = 20 * time.Millisecond
+	q.attemptTimeout = time.Second
+	q.start()
+	t.Cleanup(func() { q.Close(context.Background()) })
+	return q
+}
+
+// export posts body through the queue's client, as an OTLP exporter does.
+func export(t *testing.T, q *persistentQueue, url, body string) {
+	t.Helper()
+	resp, err := q.client().Post(url+"/v1/traces", "application/x-protobuf", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected the queue to accept the export, got %s", resp.Status)
+	}
+}
+
+// segmentNames returns the segment files in dir, oldest first.
+func segmentNames(dir string) []string {
+	matches, _ := filepath.Glob(filepath.Join(dir, "*"+segmentSuffix))
+	slices.Sort(matches)
+	return matches
+}
+
+func TestPersistentQueue(t *testing.T) {
+	t.Run("RetriesUntilCollectorRecovers", func(t *testing.T) {
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		q := openTestQueue(t, t.TempDir(), 0)
+
+		export(t, q, collector.URL, "batch-1")
+		export(t, q, collector.URL, "batch-2")
+		waitFor(t, "retries", func() bool { return collector.attemptCount() >= 3 })
+		if q.pending() != 2 {
+			t.Errorf("Expected both batches to stay queued during the outage, got %d", q.pending())
+		}
+
+		collector.status.Store(http.StatusOK)
+		waitFor(t, "delivery", func() bool { return q.pending() == 0 })
+		if got := collector.received(); !slices.Equal(got, []string{"batch-1", "batch-2"}) {
+			t.Errorf("Expected the batches in order, got %v", got)
+		}
+		if collector.types[0] != "application/x-protobuf" {
+			t.Errorf("Expected the request headers to be replayed, got %q", collector.types[0])
+		}
+	})
+
+	t.Run("ReplaysOnRestart", func(t *testing.T) {
+		dir := t.TempDir()
+		down := newCollectorStub(t, http.StatusOK)
+		downURL := down.URL
+		down.Close()
+
+		q := openTestQueue(t, dir, 0)
+		for i := 1; i <= 3; i++ {
+			export(t, q, downURL, fmt.Sprintf("batch-%d", i))
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		q.Close(ctx)
+		if n := len(segmentNames(dir)); n != 3 {
+			t.Fatalf("Expected 3 segments kept on disk, got %d", n)
+		}
+
+		// The collector comes back at the address the segments were queued for
+		collector := newCollectorStub(t, http.StatusOK)
+		for _, path := range segmentNames(dir) {
+			header, body, err := readSegment(path)
+			if err != nil {
+				t.Fatalf("Failed to read segment: %v", err)
+			}
+			header.URL = strings.Replace(header.URL, downURL, collector.URL, 1)
+			data, _ := encodeSegment(header, body)
+			os.WriteFile(path, data, 0o644)
+		}
+
+		restarted := openTestQueue(t, dir, 0)
+		waitFor(t, "replay", func() bool { return restarted.pending() == 0 })
+		if got := collector.received(); !slices.Equal(got, []string{"batch-1", "batch-2", "batch-3"}) {
+			t.Errorf("Expected the batches replayed in order, got %v", got)
+		}
+		if n := len(segmentNames(dir)); n != 0 {
+			t.Errorf("Expected delivered segments to be removed, got %d", n)
+		}
+	})
+
+	t.Run("CorruptSegments", func(t *testing.T) {
+		dir := t.TempDir()
+		collector := newCollectorStub(t, http.StatusOK)
+
+		good, _ := encodeSegment(segmentHeader{URL: collector.URL + "/v1/logs"}, []byte("good"))
+		corrupt, _ := encodeSegment(segmentHeader{URL: collector.URL + "/v1/logs"}, []byte("flipped"))
+		corrupt[len(corrupt)-6] ^= 0xff
+		os.WriteFile(filepath.Join(dir, "00000000000000000001.seg"), corrupt, 0o644)
+		os.WriteFile(filepath.Join(dir, "00000000000000000002.seg"), []byte("OKQ1"), 0o644)
+		os.WriteFile(filepath.Join(dir, "00000000000000000003.seg"), good, 0o644)
+		os.WriteFile(filepath.Join(dir, "00000000000000000004.seg.tmp"), good[:10], 0o644)
+
+		q := openTestQueue(t, dir, 0)
+		waitFor(t, "delivery", func() bool { return q.pending() == 0 })
+		if got := collector.received(); !slices.Equal(got, []string{"good"}) {
+			t.Errorf("Expected only the intact segment to be delivered, got %v", got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 || entries[0].Name() != lockFile {
+			t.Errorf("Expected corrupt and partial segments to be removed, got %d files", len(entries))
+		}
+
+		export(t, q, collector.URL, "after-restart")
+		waitFor(t, "delivery", func() bool { return len(collector.received()) == 2 })
+		if names := segmentNames(dir); len(names) != 0 {
+			t.Errorf("Expected no leftover segments, got %v", names)
+		}
+	})
+
+	t.Run("BoundedDiskUsage", func(t *testing.T) {
+		dir := t.TempDir()
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		segment, _ := encodeSegment(segmentHeader{URL: collector.URL + "/v1/traces", Header: http.Header{"Content-Type": {"application/x-protobuf"}}}, []byte("batch-0"))
+		q := openTestQueue(t, dir, int64(2*len(segment)+len(segment)/2))
+
+		for i := 0; i < 5; i++ {
+			export(t, q, collector.URL, fmt.Sprintf("batch-%d", i))
+		}
+		names := segmentNames(dir)
+		if len(names) != 2 {
+			t.Fatalf("Expected the queue to keep 2 segments, got %d", len(names))
+		}
+		for i, path := range names {
+			if _, body, _ := readSegment(path); string(body) != fmt.Sprintf("batch-%d", i+3) {
+				t.Errorf("Expected the newest batches to be kept, got %q", body)
+			}
+		}
+
+		resp, err := q.client().Post(collector.URL+"/v1/traces", "application/x-protobuf", strings.NewReader(strings.Repeat("x", 10*len(segment))))
+		if err == nil {
+			resp.Body.Close()
+			t.Error("Expected an export larger than the queue to fail")
+		}
+	})
+
+	t.Run("RejectedRequestsDropped", func(t *testing.T) {
+		collector := newCollectorStub(t, http.StatusBadRequest)
+		q := openTestQueue(t, t.TempDir(), 0)
+
+		export(t, q, collector.URL, "invalid")
+		waitFor(t, "rejection", func() bool { return q.pending() == 0 })
+		if n := collector.attemptCount(); n != 1 {
+			t.Errorf("Expected a rejected request not to be retried, got %d attempts", n)
+		}
+	})
+
+	t.Run("CredentialsNotStored", func(t *testing.T) {
+		dir := t.TempDir()
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		q := openTestQueue(t, dir, 0)
+
+		req, _ := http.NewRequest(http.MethodPost, collector.URL+"/v1/traces", strings.NewReader("secret-batch"))
+		req.Header.Set("Content-Type", "application/x-protobuf")
+		req.Header.Set("Authorization", "Bearer s3cr3t")
+		resp, err := q.client().Do(req)
+		if err != nil {
+			t.Fatalf("Export failed: %v", err)
+		}
+		resp.Body.Close()
+
+		if info, err := os.Stat(dir); err != nil || info.Mode().Perm() != 0o700 {
+			t.Errorf("Expected the queue directory to be private, got %v", info.Mode().Perm())
+		}
+		for _, path := range segmentNames(dir) {
+			data, _ := os.ReadFile(path)
+			if strings.Contains(string(data), "s3cr3t") {
+				t.Error("Expected the Authorization header not to be written to disk")
+			}
+			if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+				t.Errorf("Expected segments readable by the owner only, got %v", info.Mode().Perm())
+			}
+		}
+
+		collector.status.Store(http.StatusOK)
+		waitFor(t, "delivery", func() bool { return q.pending() == 0 })
+		if collector.auth[0] != "Bearer s3cr3t" || collector.types[0] != "application/x-protobuf" {
+			t.Errorf("Expected the exporter headers on delivery, got %q and %q", collector.auth[0], collector.types[0])
+		}
+	})
+
+	t.Run("ReplayUsesEnvHeaders", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "Authorization=Bearer%20from-env")
+		headers := otlpHeadersFromEnv("traces")
+		if got := headers.Get("Authorization"); got != "Bearer from-env" {
+			t.Errorf("Expected the decoded header, got %q", got)
+		}
+	})
+
+	t.Run("DirectoryLocked", func(t *testing.T) {
+		dir := t.TempDir()
+		q := openTestQueue(t, dir, 0)
+		if _, err := openPersistentQueue(dir, 0); err == nil || !strings.Contains(err.Error(), "another persistent queue") {
+			t.Fatalf("Expected a second queue on the directory to be refused, got %v", err)
+		}
+
+		q.Close(context.Background())
+		reopened, err := openPersistentQueue(dir, 0)
+		if err != nil {
+			t.Fatalf("Expected the directory to be free after Close, got %v", err)
+		}
+		reopened.start()
+		reopened.Close(context.Background())
+	})
+
+	t.Run("CloseDuringBackoff", func(t *testing.T) {
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		q := openTestQueue(t, t.TempDir(), 0)
+		q.initialBackoff = time.Minute
+		q.maxBackoff = time.Minute
+
+		export(t, q, collector.URL, "batch")
+		waitFor(t, "first attempt", func() bool { return collector.attemptCount() >= 1 })
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		start := time.Now()
+		if err := q.Close(ctx); err != nil {
+			t.Errorf("Expected Close to return before the deadline, got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected Close not to wait out the backoff, took %s", elapsed)
+		}
+		if n := collector.attemptCount(); n != 2 {
+			t.Errorf("Expected one last delivery attempt on Close, got %d attempts", n)
+		}
+	})
+
+	t.Run("Exporters", func(t *testing.T) {
+		dir := t.TempDir()
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		config := Config{
+			ServiceName:        "queued",
+			ExporterType:       ExporterOTLP,
+			OTLPEndpoint:       strings.TrimPrefix(collector.URL, "http://"),
+			SampleRate:         1,
+			PersistentQueueDir: dir,
+		}
+
+		kit, err := New(config)
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		_, span := kit.StartSpan(context.Background(), "during-outage")
+		span.End()
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+		kit.Shutdown(ctx)
+
+		queueDir := filepath.Join(dir, "traces-"+strings.ReplaceAll(config.OTLPEndpoint, ":", "_"))
+		if n := len(segmentNames(queueDir)); n != 1 {
+			t.Fatalf("Expected the span batch to be queued on disk, got %d segments", n)
+		}
+
+		collector.status.Store(http.StatusOK)
+		kit, err = New(config)
+		if err != nil {
+			t.Fatalf("Failed to restart OTelKit: %v", err)
+		}
+		waitFor(t, "replay", func() bool { return len(collector.received()) == 1 })
+		kit.Shutdown(context.Background())
+		if !strings.Contains(collector.received()[0], "during-outage") {
+			t.Error("Expected the replayed request to contain the span")
+		}
+	})
+}
@@ -0,0 +1,435 @@
+package otelkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Signal names of the pipeline metrics' signal attribute.
+const (
+	signalTraces  = "traces"
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+)
+
+// pipelineMetrics observes otelkit's own telemetry pipeline: what each exporter
+// sent, failed to send and dropped, and how many items wait in its queue. The
+// exporters are created before the meter provider, so the counts are kept in
+// exporterStats and reported by observable instruments once metrics start.
+//
+// Fields:
+//   - exporters: Stats of the exporters wrapped so far
+//   - duration: otelkit_exporter_export_duration_seconds, set by register
+type pipelineMetrics struct {
+	mu        sync.Mutex
+	exporters []*exporterStats
+
+	duration atomic.Pointer[metric.Float64Histogram]
+}
+
+// exporterStats counts the items (spans, metric data points or log records)
+// passing through one exporter of a signal. For an exporter with a persistent
+// queue, the queue counts what it delivers, fails to deliver and drops, and
+// persisted holds the items waiting on disk.
+type exporterStats struct {
+	pipeline *pipelineMetrics
+	attrs    attribute.Set
+	track    sync.Once
+
+	sent      atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+	queued    atomic.Int64
+	persisted atomic.Int64
+
+	persistent atomic.Bool
+	drained    atomic.Bool
+}
+
+// exporter returns the stats for the exporter named name; they are reported
+// once they wrap an exporter. A nil pipeline returns nil stats, which wrap nothing.
+func (p *pipelineMetrics) exporter(signal, name string) *exporterStats {
+	if p == nil {
+		return nil
+	}
+	return &exporterStats{
+		pipeline: p,
+		attrs:    attribute.NewSet(attribute.String("signal", signal), attribute.String("exporter", name)),
+	}
+}
+
+// exporterName names the i-th exporter of a signal after its type, numbering
+// repeated types from the second one on (otlp, otlp/2).
+func exporterName(exporters []ExporterConfig, i int) string {
+	n := 1
+	for _, e := range exporters[:i] {
+		if e.Type == exporters[i].Type {
+			n++
+		}
+	}
+	if n == 1 {
+		return string(exporters[i].Type)
+	}
+	return fmt.Sprintf("%s/%d", exporters[i].Type, n)
+}
+
+// register creates the pipeline instruments on meter.
+func (p *pipelineMetrics) register(meter metric.Meter) error {
+	counters := []struct {
+		name, description string
+		value             func(*exporterStats) int64
+	}{
+		{"otelkit_exporter_sent_total", "Spans, metric data points and log records exported successfully", func(s *exporterStats) int64 { return s.sent.Load() }},
+		{"otelkit_exporter_failed_total", "Spans, metric data points and log records in exports that failed", func(s *exporterStats) int64 { return s.failed.Load() }},
+		{"otelkit_dropped_total", "Spans, metric data points and log records dropped because the export queue or persistent queue was full", func(s *exporterStats) int64 { return s.dropped.Load() }},
+	}
+	for _, c := range counters {
+		value := c.value
+		_, err := meter.Int64ObservableCounter(c.name,
+			metric.WithDescription(c.description),
+			metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+				for _, s := range p.snapshot() {
+					observer.Observe(value(s), metric.WithAttributeSet(s.attrs))
+				}
+				return nil
+			}),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create %s counter: %w", c.name, err)
+		}
+	}
+
+	_, err := meter.Int64ObservableGauge("otelkit_queue_size",
+		metric.WithDescription("Spans, metric data points and log records waiting in the export queue or persistent queue, or being exported"),
+		metric.WithInt64Callback(func(ctx context.Context, observer metric.Int64Observer) error {
+			for _, s := range p.snapshot() {
+				if s.signal() != signalMetrics || s.persistent.Load() {
+					observer.Observe(s.queued.Load()+s.persisted.Load(), metric.WithAttributeSet(s.attrs))
+				}
+			}
+			return nil
+		}),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create otelkit_queue_size gauge: %w", err)
+	}
+
+	duration, err := meter.Float64Histogram("otelkit_exporter_export_duration_seconds",
+		metric.WithDescription("Duration of exports"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create otelkit_exporter_export_duration_seconds histogram: %w", err)
+	}
+	p.duration.Store(&duration)
+	return nil
+}
+
+// snapshot returns the stats of the wrapped exporters.
+func (p *pipelineMetrics) snapshot() []*exporterStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*exporterStats(nil), p.exporters...)
+}
+
+// signal returns the signal the stats belong to.
+func (s *exporterStats) signal() string {
+	value, _ := s.attrs.Value("signal")
+	return value.AsString()
+}
+
+// start adds the stats to the reported ones.
+func (s *exporterStats) start() {
+	s.track.Do(func() {
+		s.pipeline.mu.Lock()
+		s.pipeline.exporters = append(s.pipeline.exporters, s)
+		s.pipeline.mu.Unlock()
+	})
+}
+
+// dequeued removes n items handed to the exporter from the queue count, unless
+// drain has already counted them as dropped.
+func (s *exporterStats) dequeued(n int) {
+	if !s.drained.Load() {
+		s.queued.Add(-int64(n))
+	}
+}
+
+// drain counts the items still queued once the processor has shut down as
+// dropped: the SDK discards what it could not export before the deadline.
+func (s *exporterStats) drain() {
+	s.drained.Store(true)
+	s.dropped.Add(s.queued.Swap(0))
+}
+
+// exported records an export of n items that took duration and failed with err.
+// Behind a persistent queue an export only writes to disk, so only requests
+// that could not be queued count; the queue records its deliveries.
+func (s *exporterStats) exported(ctx context.Context, n int, duration time.Duration, err error) {
+	if s.persistent.Load() {
+		if err != nil {
+			s.failed.Add(int64(n))
+		}
+		return
+	}
+	s.record(ctx, int64(n), duration, err)
+}
+
+// record counts a delivery of n items that took duration and failed with err.
+func (s *exporterStats) record(ctx context.Context, n int64, duration time.Duration, err error) {
+	if err != nil {
+		s.failed.Add(n)
+	} else {
+		s.sent.Add(n)
+	}
+	if histogram := s.pipeline.duration.Load(); histogram != nil {
+		(*histogram).Record(ctx, duration.Seconds(), metric.WithAttributeSet(s.attrs))
+	}
+}
+
+// spanExporter wraps exporter to count its exports; nil stats return it unchanged.
+func (s *exporterStats) spanExporter(exporter sdktrace.SpanExporter) sdktrace.SpanExporter {
+	if s == nil || exporter == nil {
+		return exporter
+	}
+	s.start()
+	if queued, ok := exporter.(queuedSpanExporter); ok {
+		queued.queue.track(s)
+	}
+	return instrumentedSpanExporter{exporter, s}
+}
+
+// metricExporter wraps exporter to count its exports; nil stats return it unchanged.
+func (s *exporterStats) metricExporter(exporter sdkmetric.Exporter) sdkmetric.Exporter {
+	if s == nil {
+		return exporter
+	}
+	s.start()
+	if queued, ok := exporter.(queuedMetricExporter); ok {
+		queued.queue.track(s)
+	}
+	return instrumentedMetricExporter{exporter, s}
+}
+
+// logExporter wraps exporter to count its exports; nil stats return it unchanged.
+func (s *exporterStats) logExporter(exporter sdklog.Exporter) sdklog.Exporter {
+	if s == nil || exporter == nil {
+		return exporter
+	}
+	s.start()
+	if queued, ok := exporter.(queuedLogExporter); ok {
+		queued.queue.track(s)
+	}
+	return instrumentedLogExporter{exporter, s}
+}
+
+// instrumentedSpanExporter counts the spans exported through SpanExporter.
+type instrumentedSpanExporter struct {
+	sdktrace.SpanExporter
+	stats *exporterStats
+}
+
+func (e instrumentedSpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	e.stats.dequeued(len(spans))
+	start := time.Now()
+	err := e.SpanExporter.ExportSpans(withExportItems(ctx, len(spans)), spans)
+	e.stats.exported(ctx, len(spans), time.Since(start), err)
+	return err
+}
+
+// instrumentedMetricExporter counts the data points exported through Exporter.
+type instrumentedMetricExporter struct {
+	sdkmetric.Exporter
+	stats *exporterStats
+}
+
+func (e instrumentedMetricExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	start := time.Now()
+	n := dataPoints(rm)
+	err := e.Exporter.Export(withExportItems(ctx, n), rm)
+	e.stats.exported(ctx, n, time.Since(start), err)
+	return err
+}
+
+// instrumentedLogExporter counts the records exported through Exporter.
+type instrumentedLogExporter struct {
+	sdklog.Exporter
+	stats *exporterStats
+}
+
+func (e instrumentedLogExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.stats.dequeued(len(records))
+	start := time.Now()
+	err := e.Exporter.Export(withExportItems(ctx, len(records)), records)
+	e.stats.exported(ctx, len(records), time.Since(start), err)
+	return err
+}
+
+// dataPoints counts the data points in rm.
+func dataPoints(rm *metricdata.ResourceMetrics) int {
+	n := 0
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Gauge[int64]:
+				n += len(data.DataPoints)
+			case metricdata.Gauge[float64]:
+				n += len(data.DataPoints)
+			case metricdata.Sum[int64]:
+				n += len(data.DataPoints)
+			case metricdata.Sum[float64]:
+				n += len(data.DataPoints)
+			case metricdata.Histogram[int64]:
+				n += len(data.DataPoints)
+			case metricdata.Histogram[float64]:
+				n += len(data.DataPoints)
+			case metricdata.ExponentialHistogram[int64]:
+				n += len(data.DataPoints)
+			case metricdata.ExponentialHistogram[float64]:
+				n += len(data.DataPoints)
+			case metricdata.Summary:
+				n += len(data.DataPoints)
+			}
+		}
+	}
+	return n
+}
+
+// queueLimit returns the number of items an exporter's processor may hold:
+// unbounded for a synchronous processor, otherwise the queue size the SDK
+// batch processor uses, from maxQueueSize or, when it is 0, as the SDK
+// resolves it from envKey and its default.
+func queueLimit(maxQueueSize int, envKey string, synchronous bool) int64 {
+	if synchronous {
+		return math.MaxInt64
+	}
+	if maxQueueSize > 0 {
+		return int64(maxQueueSize)
+	}
+	return int64(getEnvIntOrDefault(envKey, defaultBatchMaxQueueSize))
+}
+
+// countingSpanProcessor sits in front of an exporter's span processor and
+// counts the spans it holds until they are handed to the instrumented exporter.
+// The count covers the batch processor's queue and the batch being gathered, so
+// it reaches limit no later than the queue fills: spans beyond it are dropped
+// here and counted, and the batch processor never drops one uncounted. Spans
+// still queued when Shutdown gives up are counted as dropped as well.
+type countingSpanProcessor struct {
+	sdktrace.SpanProcessor
+	stats   *exporterStats
+	limit   int64
+	stopped atomic.Bool
+}
+
+func (p *countingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	// Exporting processors ignore unsampled spans and spans after Shutdown
+	if !s.SpanContext().IsSampled() || p.stopped.Load() {
+		return
+	}
+	if p.stats.queued.Add(1) > p.limit {
+		p.stats.queued.Add(-1)
+		p.stats.dropped.Add(1)
+		return
+	}
+	p.SpanProcessor.OnEnd(s)
+}
+
+func (p *countingSpanProcessor) Shutdown(ctx context.Context) error {
+	p.stopped.Store(true)
+	err := p.SpanProcessor.Shutdown(ctx)
+	p.stats.drain()
+	return err
+}
+
+// countingLogProcessor is the log counterpart of countingSpanProcessor.
+type countingLogProcessor struct {
+	sdklog.Processor
+	stats   *exporterStats
+	limit   int64
+	stopped atomic.Bool
+}
+
+func (p *countingLogProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if p.stopped.Load() {
+		return nil
+	}
+	if p.stats.queued.Add(1) > p.limit {
+		p.stats.queued.Add(-1)
+		p.stats.dropped.Add(1)
+		return nil
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
+
+func (p *countingLogProcessor) Shutdown(ctx context.Context) error {
+	p.stopped.Store(true)
+	err := p.Processor.Shutdown(ctx)
+	p.stats.drain()
+	return err
+}
+
+// defaultErrorHandler is the OpenTelemetry error handler in place before any
+// is set. Once a handler is set it delegates to that one, so a restored kit
+// handler must not forward to it.
+var defaultErrorHandler = otel.GetErrorHandler()
+
+// sdkErrorHandler logs SDK errors to a kit's logger while active; afterwards
+// it forwards them to the handler it replaced.
+type sdkErrorHandler struct {
+	logger   *slog.Logger
+	previous otel.ErrorHandler
+	active   atomic.Bool
+}
+
+// Handle implements otel.ErrorHandler.
+func (h *sdkErrorHandler) Handle(err error) {
+	switch {
+	case h.active.Load():
+		h.logger.LogAttrs(context.Background(), slog.LevelError, "OpenTelemetry SDK error", slog.Any("error", err))
+	case h.previous == defaultErrorHandler:
+		// What the default handler does without a delegate
+		log.Print(err)
+	default:
+		h.previous.Handle(err)
+	}
+}
+
+// handleSDKErrors routes errors the OpenTelemetry SDK reports through
+// otel.Handle (failed exports, persistent queue retries, ...) to the kit's
+// logger until Shutdown. They are not emitted as OpenTelemetry logs: a failing
+// log exporter would otherwise export its own errors.
+func (o *OTelKit) handleSDKErrors() {
+	if o.logger == nil {
+		return
+	}
+	o.errorHandler = &sdkErrorHandler{logger: o.logger, previous: otel.GetErrorHandler()}
+	o.errorHandler.active.Store(true)
+	otel.SetErrorHandler(o.errorHandler)
+}
+
+// restoreErrorHandler stops logging SDK errors through the kit and, unless
+// another handler was set since, reinstates the one handleSDKErrors replaced.
+func (o *OTelKit) restoreErrorHandler() {
+	if o.errorHandler == nil || !o.errorHandler.active.Swap(false) {
+		return
+	}
+	if otel.GetErrorHandler() == otel.ErrorHandler(o.errorHandler) {
+		otel.SetErrorHandler(o.errorHandler.previous)
+	}
+}
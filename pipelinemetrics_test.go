@@ -0,0 +1,277 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// gatedExporter is a span and log exporter whose exports block until release
+// is closed and then fail with err.
+type gatedExporter struct {
+	release chan struct{}
+	err     error
+}
+
+func (e *gatedExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	<-e.release
+	return e.err
+}
+
+func (e *gatedExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	<-e.release
+	return e.err
+}
+
+func (e *gatedExporter) Shutdown(ctx context.Context) error   { return nil }
+func (e *gatedExporter) ForceFlush(ctx context.Context) error { return nil }
+
+// endSpans starts and ends n spans on provider.
+func endSpans(provider *sdktrace.TracerProvider, n int) {
+	for i := 0; i < n; i++ {
+		_, span := provider.Tracer("test").Start(context.Background(), "queued")
+		span.End()
+	}
+}
+
+// newPipelineMetrics returns pipeline metrics registered with a manual reader.
+func newPipelineMetrics(t *testing.T) (*pipelineMetrics, *sdkmetric.ManualReader) {
+	t.Helper()
+	reader := sdkmetric.NewManualReader()
+	pipeline := &pipelineMetrics{}
+	if err := pipeline.register(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test")); err != nil {
+		t.Fatalf("Failed to register pipeline metrics: %v", err)
+	}
+	return pipeline, reader
+}
+
+func TestPipelineMetrics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("QueueFull", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		stats := pipeline.exporter(signalTraces, "gated")
+		exporter := &gatedExporter{release: make(chan struct{})}
+		processor := newSpanProcessor(stats.spanExporter(exporter), BatchProcessorConfig{MaxQueueSize: 2, MaxExportBatchSize: 2, ScheduleDelay: time.Hour}, false, stats)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+		defer provider.Shutdown(ctx)
+
+		// The first two spans fill a batch that blocks in the export
+		endSpans(provider, 2)
+		waitFor(t, "export", func() bool { return stats.queued.Load() == 0 })
+
+		// The next two fill the queue
+		endSpans(provider, 3)
+		series := "exporter=gated,signal=traces"
+		if dropped := sumSeries(t, reader, "otelkit_dropped_total")[series]; dropped != 1 {
+			t.Errorf("Expected 1 span dropped, got %d", dropped)
+		}
+		if queued := findMetric(t, reader, "otelkit_queue_size").Data.(metricdata.Gauge[int64]).DataPoints[0].Value; queued != 2 {
+			t.Errorf("Expected 2 queued spans, got %d", queued)
+		}
+
+		close(exporter.release)
+		if err := provider.ForceFlush(ctx); err != nil {
+			t.Fatalf("ForceFlush failed: %v", err)
+		}
+		if sent := sumSeries(t, reader, "otelkit_exporter_sent_total")[series]; sent != 4 {
+			t.Errorf("Expected 4 spans sent, got %d", sent)
+		}
+		if queued := stats.queued.Load(); queued != 0 {
+			t.Errorf("Expected an empty queue, got %d", queued)
+		}
+	})
+
+	t.Run("ShutdownDropsQueued", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		stats := pipeline.exporter(signalTraces, "gated")
+		exporter := &gatedExporter{release: make(chan struct{})}
+		defer close(exporter.release)
+		processor := newSpanProcessor(stats.spanExporter(exporter), BatchProcessorConfig{MaxQueueSize: 4, MaxExportBatchSize: 2, ScheduleDelay: time.Hour}, false, stats)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+
+		endSpans(provider, 2)
+		waitFor(t, "export", func() bool { return stats.queued.Load() == 0 })
+		endSpans(provider, 2)
+
+		// The blocked export outlives the deadline, so the queued spans are discarded
+		shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+		defer cancel()
+		provider.Shutdown(shutdownCtx)
+
+		series := "exporter=gated,signal=traces"
+		if dropped := sumSeries(t, reader, "otelkit_dropped_total")[series]; dropped != 2 {
+			t.Errorf("Expected the 2 queued spans dropped, got %d", dropped)
+		}
+		if queued := findMetric(t, reader, "otelkit_queue_size").Data.(metricdata.Gauge[int64]).DataPoints[0].Value; queued != 0 {
+			t.Errorf("Expected an empty queue after Shutdown, got %d", queued)
+		}
+	})
+
+	t.Run("FailedExports", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		stats := pipeline.exporter(signalLogs, "gated")
+		exporter := &gatedExporter{release: make(chan struct{}), err: errors.New("collector unavailable")}
+		close(exporter.release)
+		processor := newLogProcessor(stats.logExporter(exporter), BatchProcessorConfig{Synchronous: true}, false, stats)
+
+		var record sdklog.Record
+		processor.OnEmit(ctx, &record)
+		processor.OnEmit(ctx, &record)
+
+		series := "exporter=gated,signal=logs"
+		if failed := sumSeries(t, reader, "otelkit_exporter_failed_total")[series]; failed != 2 {
+			t.Errorf("Expected 2 failed records, got %d", failed)
+		}
+		if sent := sumSeries(t, reader, "otelkit_exporter_sent_total")[series]; sent != 0 {
+			t.Errorf("Expected no records sent, got %d", sent)
+		}
+		duration := findMetric(t, reader, "otelkit_exporter_export_duration_seconds").Data.(metricdata.Histogram[float64])
+		if duration.DataPoints[0].Count != 2 {
+			t.Errorf("Expected 2 timed exports, got %d", duration.DataPoints[0].Count)
+		}
+		if stats.queued.Load() != 0 {
+			t.Errorf("Expected an empty queue, got %d", stats.queued.Load())
+		}
+	})
+
+	t.Run("MetricsExporter", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		collector := newOTLPStub(t)
+		readers, err := createMetricReaders(Config{MetricsExporterType: ExporterOTLP, OTLPEndpoint: collector.endpoint()}, pipeline)
+		if err != nil {
+			t.Fatalf("Failed to create metric readers: %v", err)
+		}
+		provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(readers[0]))
+		counter, _ := provider.Meter("test").Int64Counter("orders_total")
+		counter.Add(ctx, 1)
+		provider.Shutdown(ctx)
+
+		if sent := sumSeries(t, reader, "otelkit_exporter_sent_total")["exporter=otlp,signal=metrics"]; sent != 1 {
+			t.Errorf("Expected 1 data point sent, got %d", sent)
+		}
+		for _, m := range collectMetrics(t, reader) {
+			if m.Name == "otelkit_queue_size" {
+				t.Errorf("Expected no queue size for metrics exporters, got %v", m.Data)
+			}
+		}
+	})
+
+	t.Run("PersistentQueue", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		queue := openTestQueue(t, t.TempDir(), 0)
+		otlp, err := otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpoint(strings.TrimPrefix(collector.URL, "http://")),
+			otlptracehttp.WithInsecure(),
+			otlptracehttp.WithHTTPClient(queue.client()),
+		)
+		if err != nil {
+			t.Fatalf("Failed to create exporter: %v", err)
+		}
+		stats := pipeline.exporter(signalTraces, "otlp")
+		processor := newSpanProcessor(stats.spanExporter(queuedSpanExporter{otlp, queue}), BatchProcessorConfig{Synchronous: true}, false, stats)
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(processor))
+		defer provider.Shutdown(ctx)
+
+		for i := 0; i < 3; i++ {
+			_, span := provider.Tracer("test").Start(ctx, "queued")
+			span.End()
+		}
+		waitFor(t, "failed deliveries", func() bool { return collector.attemptCount() >= 3 })
+
+		series := "exporter=otlp,signal=traces"
+		if failed := sumSeries(t, reader, "otelkit_exporter_failed_total")[series]; failed < 1 {
+			t.Errorf("Expected failed deliveries during the outage, got %d", failed)
+		}
+		if sent := sumSeries(t, reader, "otelkit_exporter_sent_total")[series]; sent != 0 {
+			t.Errorf("Expected nothing sent during the outage, got %d", sent)
+		}
+		if queued := findMetric(t, reader, "otelkit_queue_size").Data.(metricdata.Gauge[int64]).DataPoints[0].Value; queued != 3 {
+			t.Errorf("Expected 3 spans in the persistent queue, got %d", queued)
+		}
+
+		collector.status.Store(http.StatusOK)
+		waitFor(t, "delivery", func() bool { return queue.pending() == 0 })
+		if sent := sumSeries(t, reader, "otelkit_exporter_sent_total")[series]; sent != 3 {
+			t.Errorf("Expected 3 spans sent after recovery, got %d", sent)
+		}
+		if queued := findMetric(t, reader, "otelkit_queue_size").Data.(metricdata.Gauge[int64]).DataPoints[0].Value; queued != 0 {
+			t.Errorf("Expected an empty persistent queue, got %d", queued)
+		}
+	})
+
+	t.Run("PersistentQueueDrops", func(t *testing.T) {
+		pipeline, reader := newPipelineMetrics(t)
+		collector := newCollectorStub(t, http.StatusServiceUnavailable)
+		segment, _ := encodeSegment(segmentHeader{URL: collector.URL + "/v1/metrics", Header: http.Header{"Content-Type": {"application/x-protobuf"}}, Items: 2}, []byte("batch-0"))
+		queue := openTestQueue(t, t.TempDir(), int64(2*len(segment)+len(segment)/2))
+		queue.track(pipeline.exporter(signalMetrics, "otlp"))
+		queue.stats.start()
+
+		for i := 0; i < 4; i++ {
+			req, _ := http.NewRequestWithContext(withExportItems(ctx, 2), http.MethodPost, collector.URL+"/v1/metrics", strings.NewReader(fmt.Sprintf("batch-%d", i)))
+			req.Header.Set("Content-Type", "application/x-protobuf")
+			resp, err := queue.client().Do(req)
+			if err != nil {
+				t.Fatalf("Export failed: %v", err)
+			}
+			resp.Body.Close()
+		}
+
+		series := "exporter=otlp,signal=metrics"
+		if dropped := sumSeries(t, reader, "otelkit_dropped_total")[series]; dropped != 4 {
+			t.Errorf("Expected the 2 oldest requests of 2 data points dropped, got %d", dropped)
+		}
+		if queued := findMetric(t, reader, "otelkit_queue_size").Data.(metricdata.Gauge[int64]).DataPoints[0].Value; queued != 4 {
+			t.Errorf("Expected 4 data points in the persistent queue, got %d", queued)
+		}
+	})
+
+	t.Run("ExporterNames", func(t *testing.T) {
+		exporters := []ExporterConfig{{Type: ExporterOTLP}, {Type: ExporterStdout}, {Type: ExporterOTLP}}
+		var names []string
+		for i := range exporters {
+			names = append(names, exporterName(exporters, i))
+		}
+		if got := strings.Join(names, ","); got != "otlp,stdout,otlp/2" {
+			t.Errorf("Unexpected exporter names: %s", got)
+		}
+	})
+}
+
+func TestSDKErrorHandler(t *testing.T) {
+	previous := otel.GetErrorHandler()
+
+	logs := &syncBuffer{}
+	kit := &OTelKit{logger: slog.New(slog.NewJSONHandler(logs, nil))}
+	kit.handleSDKErrors()
+
+	otel.Handle(errors.New("traces export: connection refused"))
+	if out := logs.String(); !strings.Contains(out, "OpenTelemetry SDK error") || !strings.Contains(out, "connection refused") {
+		t.Errorf("Expected the SDK error in the kit's log, got %q", out)
+	}
+
+	if err := kit.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Failed to shut down: %v", err)
+	}
+	if otel.GetErrorHandler() != previous {
+		t.Error("Expected Shutdown to restore the previous error handler")
+	}
+	otel.Handle(errors.New("after shutdown"))
+	if strings.Contains(logs.String(), "after shutdown") {
+		t.Error("Expected no SDK errors in the log of a shut down kit")
+	}
+}
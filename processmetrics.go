@@ -0,0 +1,168 @@
+package otelkit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/processconv"
+)
+
+// clockTicks is USER_HZ, the unit of the CPU times in /proc/self/stat; it is
+// 100 on every Linux architecture Go supports.
+const clockTicks = 100
+
+// procStat holds the fields otelkit reads from /proc/self/stat.
+//
+// Fields:
+//   - userTicks, systemTicks: CPU time spent in user and kernel mode, in clock ticks
+//   - threads: Number of OS threads
+//   - startTicks: Process start time, in clock ticks after boot
+//   - virtualBytes: Virtual memory size
+//   - residentPages: Resident set size, in pages
+type procStat struct {
+	userTicks     uint64
+	systemTicks   uint64
+	threads       int64
+	startTicks    uint64
+	virtualBytes  int64
+	residentPages int64
+}
+
+// parseProcStat parses the contents of /proc/<pid>/stat. The command name in
+// field 2 may contain spaces and parentheses, so fields are counted from its
+// closing parenthesis.
+func parseProcStat(data []byte) (procStat, error) {
+	end := bytes.LastIndexByte(data, ')')
+	if end < 0 {
+		return procStat{}, fmt.Errorf("invalid /proc stat: missing command name")
+	}
+	// fields[0] is field 3 (state)
+	fields := bytes.Fields(data[end+1:])
+	if len(fields) < 22 {
+		return procStat{}, fmt.Errorf("invalid /proc stat: %d fields", len(fields)+2)
+	}
+
+	var stat procStat
+	var err error
+	parse := func(field int) uint64 {
+		var v uint64
+		if err == nil {
+			v, err = strconv.ParseUint(string(fields[field-3]), 10, 64)
+		}
+		return v
+	}
+	stat.userTicks = parse(14)
+	stat.systemTicks = parse(15)
+	stat.threads = int64(parse(20))
+	stat.startTicks = parse(22)
+	stat.virtualBytes = int64(parse(23))
+	stat.residentPages = int64(parse(24))
+	if err != nil {
+		return procStat{}, fmt.Errorf("invalid /proc stat: %w", err)
+	}
+	return stat, nil
+}
+
+// processMetrics reports metrics of the current process read from /proc.
+type processMetrics struct {
+	cpuTime processconv.CPUTime
+	memory  metric.Int64ObservableUpDownCounter
+	virtual metric.Int64ObservableUpDownCounter
+	fds     metric.Int64ObservableUpDownCounter
+	threads metric.Int64ObservableUpDownCounter
+	uptime  metric.Float64ObservableGauge
+}
+
+// registerProcessMetrics creates the process instruments on meter and registers
+// the callback observing them.
+//
+// Instruments (semantic convention names):
+//   - process.cpu.time: CPU seconds by cpu.mode (user, system)
+//   - process.memory.usage, process.memory.virtual: Resident and virtual memory
+//   - process.open_file_descriptor.count, process.thread.count
+//   - process.uptime: Seconds since the process started
+//
+// The values come from /proc, so they are only reported on Linux; elsewhere
+// the callback observes nothing.
+func registerProcessMetrics(meter metric.Meter) (metric.Registration, error) {
+	p := &processMetrics{}
+
+	var err error
+	if p.cpuTime, err = processconv.NewCPUTime(meter); err != nil {
+		return nil, err
+	}
+	if p.memory, err = meter.Int64ObservableUpDownCounter(processconv.MemoryUsage{}.Name(),
+		metric.WithDescription(processconv.MemoryUsage{}.Description()),
+		metric.WithUnit(processconv.MemoryUsage{}.Unit()),
+	); err != nil {
+		return nil, err
+	}
+	if p.virtual, err = meter.Int64ObservableUpDownCounter(processconv.MemoryVirtual{}.Name(),
+		metric.WithDescription(processconv.MemoryVirtual{}.Description()),
+		metric.WithUnit(processconv.MemoryVirtual{}.Unit()),
+	); err != nil {
+		return nil, err
+	}
+	if p.fds, err = meter.Int64ObservableUpDownCounter(processconv.OpenFileDescriptorCount{}.Name(),
+		metric.WithDescription(processconv.OpenFileDescriptorCount{}.Description()),
+		metric.WithUnit(processconv.OpenFileDescriptorCount{}.Unit()),
+	); err != nil {
+		return nil, err
+	}
+	if p.threads, err = meter.Int64ObservableUpDownCounter(processconv.ThreadCount{}.Name(),
+		metric.WithDescription(processconv.ThreadCount{}.Description()),
+		metric.WithUnit(processconv.ThreadCount{}.Unit()),
+	); err != nil {
+		return nil, err
+	}
+	if p.uptime, err = meter.Float64ObservableGauge(processconv.Uptime{}.Name(),
+		metric.WithDescription(processconv.Uptime{}.Description()),
+		metric.WithUnit(processconv.Uptime{}.Unit()),
+	); err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(p.observe, p.cpuTime.Inst(), p.memory, p.virtual, p.fds, p.threads, p.uptime)
+}
+
+// observe reads /proc and observes every process instrument. Files that cannot
+// be read (e.g. outside Linux) are skipped.
+func (p *processMetrics) observe(_ context.Context, o metric.Observer) error {
+	if data, err := os.ReadFile("/proc/self/stat"); err == nil {
+		if stat, err := parseProcStat(data); err == nil {
+			o.ObserveFloat64(p.cpuTime.Inst(), float64(stat.userTicks)/clockTicks,
+				metric.WithAttributes(p.cpuTime.AttrCPUMode(processconv.CPUModeUser)))
+			o.ObserveFloat64(p.cpuTime.Inst(), float64(stat.systemTicks)/clockTicks,
+				metric.WithAttributes(p.cpuTime.AttrCPUMode(processconv.CPUModeSystem)))
+			o.ObserveInt64(p.memory, stat.residentPages*int64(os.Getpagesize()))
+			o.ObserveInt64(p.virtual, stat.virtualBytes)
+			o.ObserveInt64(p.threads, stat.threads)
+			if uptime, err := systemUptime(); err == nil {
+				o.ObserveFloat64(p.uptime, uptime-float64(stat.startTicks)/clockTicks)
+			}
+		}
+	}
+
+	// The listing includes the descriptor ReadDir itself opened
+	if entries, err := os.ReadDir("/proc/self/fd"); err == nil && len(entries) > 0 {
+		o.ObserveInt64(p.fds, int64(len(entries)-1))
+	}
+	return nil
+}
+
+// systemUptime returns the seconds since boot from /proc/uptime.
+func systemUptime() (float64, error) {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0, err
+	}
+	fields := bytes.Fields(data)
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("invalid /proc/uptime")
+	}
+	return strconv.ParseFloat(string(fields[0]), 64)
+}
@@ -0,0 +1,248 @@
+package otelkit
+
+import (
+	"context"
+	"math"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/semconv/v1.37.0/goconv"
+)
+
+// runtime/metrics samples read by the runtime metrics callback.
+const (
+	rmGoroutines     = "/sched/goroutines:goroutines"
+	rmMemoryTotal    = "/memory/classes/total:bytes"
+	rmMemoryReleased = "/memory/classes/heap/released:bytes"
+	rmHeapStacks     = "/memory/classes/heap/stacks:bytes"
+	rmOSStacks       = "/memory/classes/os-stacks:bytes"
+	rmMemoryLimit    = "/gc/gomemlimit:bytes"
+	rmAllocBytes     = "/gc/heap/allocs:bytes"
+	rmAllocObjects   = "/gc/heap/allocs:objects"
+	rmHeapGoal       = "/gc/heap/goal:bytes"
+	rmHeapObjects    = "/gc/heap/objects:objects"
+	rmHeapBytes      = "/memory/classes/heap/objects:bytes"
+	rmGOMAXPROCS     = "/sched/gomaxprocs:threads"
+	rmGOGC           = "/gc/gogc:percent"
+	rmGCCycles       = "/gc/cycles/total:gc-cycles"
+	rmSchedLatencies = "/sched/latencies:seconds"
+)
+
+// runtimeMetrics reports Go runtime metrics read from runtime/metrics.
+//
+// Fields:
+//   - samples: The runtime/metrics samples, read together on every collection
+//   - index: Position of each sample by runtime/metrics name
+type runtimeMetrics struct {
+	mu      sync.Mutex
+	samples []metrics.Sample
+	index   map[string]int
+
+	goroutines  goconv.GoroutineCount
+	memoryUsed  goconv.MemoryUsed
+	memoryLimit goconv.MemoryLimit
+	allocated   goconv.MemoryAllocated
+	allocations goconv.MemoryAllocations
+	gcGoal      goconv.MemoryGCGoal
+	processors  goconv.ProcessorLimit
+	gogc        goconv.ConfigGogc
+	heapObjects metric.Int64ObservableUpDownCounter
+	heapBytes   metric.Int64ObservableUpDownCounter
+	gcCycles    metric.Int64ObservableCounter
+}
+
+// registerRuntimeMetrics creates the Go runtime instruments on meter and
+// registers the callback observing them.
+//
+// Instruments (semantic convention names where they exist):
+//   - go.goroutine.count, go.processor.limit, go.config.gogc
+//   - go.memory.used (by go.memory.type), go.memory.limit, go.memory.gc.goal
+//   - go.memory.allocated, go.memory.allocations: cumulative heap allocations
+//   - go.memory.heap.objects, go.memory.heap.used: live heap objects and their bytes
+//   - go.gc.cycles: completed GC cycles
+//
+// go.schedule.duration is a histogram, which the SDK cannot observe; it is
+// reported by runtimeProducer instead.
+func registerRuntimeMetrics(meter metric.Meter) (metric.Registration, error) {
+	names := []string{
+		rmGoroutines, rmMemoryTotal, rmMemoryReleased, rmHeapStacks, rmOSStacks,
+		rmMemoryLimit, rmAllocBytes, rmAllocObjects, rmHeapGoal, rmHeapObjects,
+		rmHeapBytes, rmGOMAXPROCS, rmGOGC, rmGCCycles,
+	}
+	r := &runtimeMetrics{
+		samples: make([]metrics.Sample, len(names)),
+		index:   make(map[string]int, len(names)),
+	}
+	for i, name := range names {
+		r.samples[i].Name = name
+		r.index[name] = i
+	}
+
+	var err error
+	if r.goroutines, err = goconv.NewGoroutineCount(meter); err != nil {
+		return nil, err
+	}
+	if r.memoryUsed, err = goconv.NewMemoryUsed(meter); err != nil {
+		return nil, err
+	}
+	if r.memoryLimit, err = goconv.NewMemoryLimit(meter); err != nil {
+		return nil, err
+	}
+	if r.allocated, err = goconv.NewMemoryAllocated(meter); err != nil {
+		return nil, err
+	}
+	if r.allocations, err = goconv.NewMemoryAllocations(meter); err != nil {
+		return nil, err
+	}
+	if r.gcGoal, err = goconv.NewMemoryGCGoal(meter); err != nil {
+		return nil, err
+	}
+	if r.processors, err = goconv.NewProcessorLimit(meter); err != nil {
+		return nil, err
+	}
+	if r.gogc, err = goconv.NewConfigGogc(meter); err != nil {
+		return nil, err
+	}
+	if r.heapObjects, err = meter.Int64ObservableUpDownCounter("go.memory.heap.objects",
+		metric.WithDescription("Count of live and unswept objects in the heap."),
+		metric.WithUnit("{object}"),
+	); err != nil {
+		return nil, err
+	}
+	if r.heapBytes, err = meter.Int64ObservableUpDownCounter("go.memory.heap.used",
+		metric.WithDescription("Memory occupied by live and unswept objects in the heap."),
+		metric.WithUnit("By"),
+	); err != nil {
+		return nil, err
+	}
+	if r.gcCycles, err = meter.Int64ObservableCounter("go.gc.cycles",
+		metric.WithDescription("Count of completed GC cycles."),
+		metric.WithUnit("{gc_cycle}"),
+	); err != nil {
+		return nil, err
+	}
+	return meter.RegisterCallback(r.observe,
+		r.goroutines.Inst(), r.memoryUsed.Inst(), r.memoryLimit.Inst(), r.allocated.Inst(),
+		r.allocations.Inst(), r.gcGoal.Inst(), r.processors.Inst(), r.gogc.Inst(),
+		r.heapObjects, r.heapBytes, r.gcCycles,
+	)
+}
+
+// observe reads the runtime samples and observes every runtime instrument.
+func (r *runtimeMetrics) observe(_ context.Context, o metric.Observer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	metrics.Read(r.samples)
+
+	total := r.uint64(rmMemoryTotal) - r.uint64(rmMemoryReleased)
+	stack := r.uint64(rmHeapStacks) + r.uint64(rmOSStacks)
+	o.ObserveInt64(r.goroutines.Inst(), r.int64(rmGoroutines))
+	o.ObserveInt64(r.memoryUsed.Inst(), int64(stack), metric.WithAttributes(r.memoryUsed.AttrMemoryType(goconv.MemoryTypeStack)))
+	o.ObserveInt64(r.memoryUsed.Inst(), int64(total-stack), metric.WithAttributes(r.memoryUsed.AttrMemoryType(goconv.MemoryTypeOther)))
+	if limit := r.int64(rmMemoryLimit); limit != math.MaxInt64 {
+		o.ObserveInt64(r.memoryLimit.Inst(), limit)
+	}
+	o.ObserveInt64(r.allocated.Inst(), r.int64(rmAllocBytes))
+	o.ObserveInt64(r.allocations.Inst(), r.int64(rmAllocObjects))
+	o.ObserveInt64(r.gcGoal.Inst(), r.int64(rmHeapGoal))
+	o.ObserveInt64(r.processors.Inst(), r.int64(rmGOMAXPROCS))
+	if gogc := r.int64(rmGOGC); gogc > 0 {
+		o.ObserveInt64(r.gogc.Inst(), gogc)
+	}
+	o.ObserveInt64(r.heapObjects, r.int64(rmHeapObjects))
+	o.ObserveInt64(r.heapBytes, r.int64(rmHeapBytes))
+	o.ObserveInt64(r.gcCycles, r.int64(rmGCCycles))
+	return nil
+}
+
+// uint64 returns the value of a uint64 sample, or 0 if the runtime does not support it.
+func (r *runtimeMetrics) uint64(name string) uint64 {
+	value := r.samples[r.index[name]].Value
+	if value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return value.Uint64()
+}
+
+// int64 is uint64 converted for the Int64 instruments.
+func (r *runtimeMetrics) int64(name string) int64 {
+	v := r.uint64(name)
+	if v > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(v)
+}
+
+// runtimeProducer reports go.schedule.duration, the time runnable goroutines
+// waited to run, with the bucket boundaries of /sched/latencies:seconds. It is
+// registered with every metric reader; as the runtime histogram is cumulative,
+// each reader gets the same counts and no state is kept between collections.
+//
+// Fields:
+//   - scope: Instrumentation scope the histogram is reported under
+//   - start: Start time of the cumulative histogram
+type runtimeProducer struct {
+	scope instrumentation.Scope
+	start time.Time
+}
+
+// newRuntimeProducer creates a runtimeProducer reporting under scope.
+func newRuntimeProducer(scope instrumentation.Scope) *runtimeProducer {
+	return &runtimeProducer{scope: scope, start: time.Now()}
+}
+
+// Produce implements sdkmetric.Producer. The runtime does not track the sum of
+// latencies, so it is estimated from the bucket midpoints.
+func (p *runtimeProducer) Produce(context.Context) ([]metricdata.ScopeMetrics, error) {
+	sample := []metrics.Sample{{Name: rmSchedLatencies}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil, nil
+	}
+	hist := sample[0].Value.Float64Histogram()
+
+	var count uint64
+	var sum float64
+	for i, n := range hist.Counts {
+		count += n
+		sum += float64(n) * bucketMidpoint(hist.Buckets[i], hist.Buckets[i+1])
+	}
+
+	schedule := goconv.ScheduleDuration{}
+	return []metricdata.ScopeMetrics{{
+		Scope: p.scope,
+		Metrics: []metricdata.Metrics{{
+			Name:        schedule.Name(),
+			Description: schedule.Description(),
+			Unit:        schedule.Unit(),
+			Data: metricdata.Histogram[float64]{
+				Temporality: metricdata.CumulativeTemporality,
+				DataPoints: []metricdata.HistogramDataPoint[float64]{{
+					StartTime:    p.start,
+					Time:         time.Now(),
+					Count:        count,
+					Sum:          sum,
+					Bounds:       append([]float64(nil), hist.Buckets[1:len(hist.Buckets)-1]...),
+					BucketCounts: append([]uint64(nil), hist.Counts...),
+				}},
+			},
+		}},
+	}}, nil
+}
+
+// bucketMidpoint returns the middle of a runtime/metrics bucket, or its finite
+// boundary when the other one is infinite.
+func bucketMidpoint(lower, upper float64) float64 {
+	switch {
+	case math.IsInf(lower, -1):
+		return upper
+	case math.IsInf(upper, 1):
+		return lower
+	}
+	return (lower + upper) / 2
+}
@@ -0,0 +1,152 @@
+package otelkit
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestRuntimeMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("runtime-test")
+	if _, err := registerRuntimeMetrics(meter); err != nil {
+		t.Fatalf("Failed to register runtime metrics: %v", err)
+	}
+
+	collectMetrics(t, reader)
+	runtime.GC()
+	names := make(map[string]metricdata.Metrics)
+	for _, m := range collectMetrics(t, reader) {
+		names[m.Name] = m
+	}
+
+	for _, name := range []string{
+		"go.goroutine.count", "go.memory.used", "go.memory.allocated", "go.memory.allocations",
+		"go.memory.gc.goal", "go.processor.limit", "go.config.gogc", "go.memory.heap.objects",
+		"go.memory.heap.used", "go.gc.cycles",
+	} {
+		if _, ok := names[name]; !ok {
+			t.Errorf("Expected metric %s", name)
+		}
+	}
+
+	goroutines := names["go.goroutine.count"].Data.(metricdata.Sum[int64]).DataPoints
+	if len(goroutines) != 1 || goroutines[0].Value < 1 {
+		t.Errorf("Expected a positive goroutine count, got %v", goroutines)
+	}
+	if n := len(names["go.memory.used"].Data.(metricdata.Sum[int64]).DataPoints); n != 2 {
+		t.Errorf("Expected go.memory.used by stack and other, got %d series", n)
+	}
+	if n := len(names["go.gc.cycles"].Data.(metricdata.Sum[int64]).DataPoints); n != 1 {
+		t.Errorf("Expected one go.gc.cycles series after runtime.GC, got %d", n)
+	}
+}
+
+func TestRuntimeProducer(t *testing.T) {
+	producer := newRuntimeProducer(instrumentation.Scope{Name: "runtime-test"})
+	first := sdkmetric.NewManualReader(sdkmetric.WithProducer(producer))
+	second := sdkmetric.NewManualReader(sdkmetric.WithProducer(producer))
+	sdkmetric.NewMeterProvider(sdkmetric.WithReader(first), sdkmetric.WithReader(second))
+
+	schedule := func(reader *sdkmetric.ManualReader) metricdata.HistogramDataPoint[float64] {
+		t.Helper()
+		m := findMetric(t, reader, "go.schedule.duration")
+		if m.Unit != "s" {
+			t.Errorf("Expected unit s, got %q", m.Unit)
+		}
+		hist := m.Data.(metricdata.Histogram[float64])
+		if hist.Temporality != metricdata.CumulativeTemporality || len(hist.DataPoints) != 1 {
+			t.Fatalf("Expected one cumulative data point, got %+v", hist)
+		}
+		return hist.DataPoints[0]
+	}
+
+	// Each reader sees every scheduling event, however often the others collect
+	var wg sync.WaitGroup
+	for range 100 {
+		wg.Add(1)
+		go func() { defer wg.Done() }()
+	}
+	wg.Wait()
+	before := schedule(first)
+	schedule(first)
+	after := schedule(second)
+	if after.Count < before.Count || after.Count == 0 {
+		t.Errorf("Expected a growing cumulative count, got %d then %d", before.Count, after.Count)
+	}
+	if len(after.Bounds)+1 != len(after.BucketCounts) {
+		t.Errorf("Expected one more bucket than bounds, got %d and %d", len(after.BucketCounts), len(after.Bounds))
+	}
+	var total uint64
+	for _, n := range after.BucketCounts {
+		total += n
+	}
+	if total != after.Count {
+		t.Errorf("Expected bucket counts to add up to %d, got %d", after.Count, total)
+	}
+}
+
+func TestProcessMetrics(t *testing.T) {
+	t.Run("ParseProcStat", func(t *testing.T) {
+		data := []byte("1234 (my (odd) app) S 1 1234 1234 0 -1 4194560 1200 0 0 0 250 75 0 0 20 0 12 0 5000 1073741824 2048 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0")
+		stat, err := parseProcStat(data)
+		if err != nil {
+			t.Fatalf("Failed to parse: %v", err)
+		}
+		want := procStat{userTicks: 250, systemTicks: 75, threads: 12, startTicks: 5000, virtualBytes: 1073741824, residentPages: 2048}
+		if stat != want {
+			t.Errorf("Expected %+v, got %+v", want, stat)
+		}
+
+		if _, err := parseProcStat([]byte("1234 (app) S 1")); err == nil {
+			t.Error("Expected an error for a truncated stat")
+		}
+	})
+
+	t.Run("Collect", func(t *testing.T) {
+		if runtime.GOOS != "linux" {
+			t.Skip("process metrics read /proc")
+		}
+
+		reader := sdkmetric.NewManualReader()
+		meter := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("process-test")
+		if _, err := registerProcessMetrics(meter); err != nil {
+			t.Fatalf("Failed to register process metrics: %v", err)
+		}
+
+		names := make(map[string]metricdata.Metrics)
+		for _, m := range collectMetrics(t, reader) {
+			names[m.Name] = m
+		}
+		for _, name := range []string{
+			"process.cpu.time", "process.memory.usage", "process.memory.virtual",
+			"process.open_file_descriptor.count", "process.thread.count", "process.uptime",
+		} {
+			if _, ok := names[name]; !ok {
+				t.Errorf("Expected metric %s", name)
+			}
+		}
+
+		rss := names["process.memory.usage"].Data.(metricdata.Sum[int64]).DataPoints
+		if len(rss) != 1 || rss[0].Value <= 0 {
+			t.Errorf("Expected a positive resident memory, got %v", rss)
+		}
+		if n := len(names["process.cpu.time"].Data.(metricdata.Sum[float64]).DataPoints); n != 2 {
+			t.Errorf("Expected user and system CPU time, got %d series", n)
+		}
+	})
+
+	t.Run("EnabledByConfig", func(t *testing.T) {
+		kit, err := New(Config{ServiceName: "runtime-metrics", ExporterType: ExporterNone, EnableMetrics: true,
+			MetricsExporterType: ExporterNone, RuntimeMetrics: true, ProcessMetrics: true})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		kit.Shutdown(context.Background())
+	})
+}
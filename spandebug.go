@@ -0,0 +1,80 @@
+package otelkit
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// openSpanInfo is one open span in the SpanDebugHandler response.
+type openSpanInfo struct {
+	Name    string    `json:"name"`
+	TraceID string    `json:"trace_id"`
+	SpanID  string    `json:"span_id"`
+	Start   time.Time `json:"start"`
+	Age     string    `json:"age"`
+	Stack   string    `json:"stack"`
+}
+
+// spanDebugReport is the SpanDebugHandler response body.
+type spanDebugReport struct {
+	LeakDetection bool             `json:"leak_detection"`
+	LeakThreshold string           `json:"leak_threshold,omitempty"`
+	ActiveSpans   map[string]int64 `json:"active_spans"`
+	OpenSpans     []openSpanInfo   `json:"open_spans"`
+}
+
+// SpanDebugHandler returns an HTTP handler that reports the spans currently open,
+// for finding spans that were never ended.
+//
+// Returns:
+//   - http.Handler: A handler responding with JSON
+//
+// The response contains the live span count per name and, when
+// Config.SpanLeakThreshold is set, every open span (oldest first) with its
+// trace and span IDs, age and creation stack trace. The optional min_age query
+// parameter (a Go duration such as "30s") only lists spans open at least that long.
+//
+// Mount it on an internal or admin port only: stack traces expose source paths.
+//
+// Example:
+//   debugMux := http.NewServeMux()
+//   debugMux.Handle("/debug/spans", kit.SpanDebugHandler())
+//   go http.ListenAndServe("localhost:6060", debugMux)
+//   // curl 'localhost:6060/debug/spans?min_age=1m'
+func (o *OTelKit) SpanDebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var minAge time.Duration
+		if value := r.URL.Query().Get("min_age"); value != "" {
+			var err error
+			if minAge, err = time.ParseDuration(value); err != nil {
+				http.Error(w, "invalid min_age: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		report := spanDebugReport{ActiveSpans: map[string]int64{}, OpenSpans: []openSpanInfo{}}
+		if p := o.activeSpans; p != nil {
+			report.ActiveSpans = p.snapshot()
+			if p.live != nil {
+				report.LeakDetection = true
+				report.LeakThreshold = p.leakThreshold.String()
+
+				now := time.Now()
+				for _, span := range p.openSpans(minAge) {
+					report.OpenSpans = append(report.OpenSpans, openSpanInfo{
+						Name:    span.name,
+						TraceID: span.spanContext.TraceID().String(),
+						SpanID:  span.spanContext.SpanID().String(),
+						Start:   span.start,
+						Age:     now.Sub(span.start).Round(time.Millisecond).String(),
+						Stack:   formatStack(span.stack),
+					})
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
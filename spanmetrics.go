@@ -0,0 +1,153 @@
+package otelkit
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// defaultSpanMetricsMaxSeries is used when Config.SpanMetricsMaxSeries is 0.
+const defaultSpanMetricsMaxSeries = 1000
+
+// spanMetricsOverflowName labels span metrics whose series did not fit in the series cap.
+const spanMetricsOverflowName = "_other"
+
+// spanMetricsProcessor is a SpanProcessor that derives RED metrics (rate,
+// errors, duration) from every ended span.
+//
+// Fields:
+//   - kit: Provides the calls_total and duration instruments
+//   - allowed: Span attributes copied onto the metrics
+//   - maxSeries: Distinct label sets recorded before spans fall into the overflow series
+//   - series: Label sets recorded so far; read without locking on every span end
+//   - count: Number of entries in series, guarded by mu
+//   - overflowed: Whether the overflow warning has been logged, guarded by mu
+type spanMetricsProcessor struct {
+	kit       *OTelKit
+	allowed   map[attribute.Key]bool
+	maxSeries int
+	series    sync.Map
+
+	mu         sync.Mutex
+	count      int
+	overflowed bool
+}
+
+// newSpanMetricsProcessor creates the processor from the span metrics settings of config.
+func newSpanMetricsProcessor(kit *OTelKit, config Config) *spanMetricsProcessor {
+	allowed := make(map[attribute.Key]bool, len(config.SpanMetricsAttributes))
+	for _, key := range config.SpanMetricsAttributes {
+		if key = strings.TrimSpace(key); key != "" {
+			allowed[attribute.Key(key)] = true
+		}
+	}
+
+	maxSeries := config.SpanMetricsMaxSeries
+	if maxSeries <= 0 {
+		maxSeries = defaultSpanMetricsMaxSeries
+	}
+
+	return &spanMetricsProcessor{
+		kit:       kit,
+		allowed:   allowed,
+		maxSeries: maxSeries,
+	}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *spanMetricsProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *spanMetricsProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if p.kit.spanCallsTotal == nil || p.kit.spanDuration == nil {
+		return
+	}
+
+	attrs := metric.WithAttributeSet(p.labels(s))
+	ctx := context.Background()
+	p.kit.spanCallsTotal.Add(ctx, 1, attrs)
+	p.kit.spanDuration.Record(ctx, s.EndTime().Sub(s.StartTime()).Seconds(), attrs)
+}
+
+// Shutdown implements sdktrace.SpanProcessor.
+func (p *spanMetricsProcessor) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush implements sdktrace.SpanProcessor.
+func (p *spanMetricsProcessor) ForceFlush(ctx context.Context) error {
+	return nil
+}
+
+// labels returns the metric attributes of a span: span.name, span.kind,
+// status.code and the allow-listed span attributes. Once maxSeries label sets
+// have been seen, new ones are replaced by span.name="_other" with only kind
+// and status, and a warning is logged the first time.
+func (p *spanMetricsProcessor) labels(s sdktrace.ReadOnlySpan) attribute.Set {
+	base := []attribute.KeyValue{
+		attribute.String("span.kind", s.SpanKind().String()),
+		attribute.String("status.code", strings.ToLower(s.Status().Code.String())),
+	}
+
+	attrs := append([]attribute.KeyValue{attribute.String("span.name", s.Name())}, base...)
+	if len(p.allowed) > 0 {
+		for _, kv := range s.Attributes() {
+			if p.allowed[kv.Key] {
+				attrs = append(attrs, kv)
+			}
+		}
+	}
+	set := attribute.NewSet(attrs...)
+
+	// Known label sets, the common case, take no lock
+	if _, ok := p.series.Load(set.Equivalent()); ok {
+		return set
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.series.Load(set.Equivalent()); ok {
+		return set
+	}
+	if p.count < p.maxSeries {
+		p.series.Store(set.Equivalent(), struct{}{})
+		p.count++
+		return set
+	}
+
+	if !p.overflowed {
+		p.overflowed = true
+		p.kit.LogWarn(context.Background(), "Span metrics series limit reached, new series are recorded as span.name=_other",
+			slog.Int("max_series", p.maxSeries),
+			slog.String("span_name", s.Name()),
+		)
+	}
+	return attribute.NewSet(append(base, attribute.String("span.name", spanMetricsOverflowName))...)
+}
+
+// recordOnlySampler wraps a sampler so that spans it drops are still recorded
+// (but not exported), letting activeSpanProcessor and spanMetricsProcessor see
+// every span regardless of the sample rate.
+type recordOnlySampler struct {
+	sdktrace.Sampler
+}
+
+// ShouldSample implements sdktrace.Sampler.
+func (s recordOnlySampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	result := s.Sampler.ShouldSample(p)
+	if result.Decision == sdktrace.Drop {
+		result.Decision = sdktrace.RecordOnly
+	}
+	return result
+}
+
+// Description implements sdktrace.Sampler.
+func (s recordOnlySampler) Description() string {
+	return "RecordOnly{" + s.Sampler.Description() + "}"
+}
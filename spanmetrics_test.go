@@ -0,0 +1,150 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// newSpanMetricsKit returns a kit whose spans feed a span metrics processor
+// built from config, and the reader collecting its metrics.
+func newSpanMetricsKit(t *testing.T, config Config) (*OTelKit, *sdkmetric.ManualReader) {
+	t.Helper()
+	config.SpanMetrics = true
+	return newMetricsKit(t, config)
+}
+
+// spanCalls returns calls_total by encoded attribute set.
+func spanCalls(t *testing.T, reader *sdkmetric.ManualReader) map[string]int64 {
+	t.Helper()
+	calls := make(map[string]int64)
+	for _, m := range collectMetrics(t, reader) {
+		if m.Name != "calls_total" {
+			continue
+		}
+		for _, dp := range m.Data.(metricdata.Sum[int64]).DataPoints {
+			calls[dp.Attributes.Encoded(attribute.DefaultEncoder())] = dp.Value
+		}
+	}
+	return calls
+}
+
+func TestSpanMetricsProcessor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("REDMetrics", func(t *testing.T) {
+		kit, reader := newSpanMetricsKit(t, Config{SpanMetricsAttributes: []string{"db.system.name"}})
+
+		kit.TraceFunction(ctx, "load_user", func(ctx context.Context) error { return nil })
+		kit.TraceFunction(ctx, "load_user", func(ctx context.Context) error { return errors.New("boom") })
+		_, span := kit.StartSpan(ctx, "query", trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(attribute.String("db.system.name", "postgresql"), attribute.String("user.id", "42")))
+		span.SetStatus(codes.Ok, "")
+		span.End()
+
+		calls := spanCalls(t, reader)
+		expected := map[string]int64{
+			"span.kind=internal,span.name=load_user,status.code=unset":                  1,
+			"span.kind=internal,span.name=load_user,status.code=error":                  1,
+			"db.system.name=postgresql,span.kind=client,span.name=query,status.code=ok": 1,
+		}
+		for key, want := range expected {
+			if calls[key] != want {
+				t.Errorf("Expected %s = %d, got %v", key, want, calls)
+			}
+		}
+		if len(calls) != len(expected) {
+			t.Errorf("Expected %d series (user.id not allow-listed), got %v", len(expected), calls)
+		}
+
+		for _, m := range collectMetrics(t, reader) {
+			if m.Name == "duration" {
+				if n := len(m.Data.(metricdata.Histogram[float64]).DataPoints); n != len(expected) {
+					t.Errorf("Expected %d duration series, got %d", len(expected), n)
+				}
+			}
+		}
+	})
+
+	t.Run("SeriesLimit", func(t *testing.T) {
+		kit, reader := newSpanMetricsKit(t, Config{SpanMetricsMaxSeries: 3})
+		logs := &syncBuffer{}
+		kit.logger = slog.New(slog.NewJSONHandler(logs, nil))
+
+		for i := 0; i < 10; i++ {
+			_, span := kit.StartSpan(ctx, fmt.Sprintf("span-%d", i))
+			span.End()
+		}
+
+		calls := spanCalls(t, reader)
+		if len(calls) != 4 {
+			t.Errorf("Expected 3 series plus overflow, got %v", calls)
+		}
+		if n := calls["span.kind=internal,span.name=_other,status.code=unset"]; n != 7 {
+			t.Errorf("Expected 7 overflow calls, got %d", n)
+		}
+		if strings.Count(logs.String(), "Span metrics series limit reached") != 1 {
+			t.Errorf("Expected one overflow warning, got:\n%s", logs.String())
+		}
+	})
+
+	t.Run("ConcurrentSeriesLimit", func(t *testing.T) {
+		kit, reader := newSpanMetricsKit(t, Config{SpanMetricsMaxSeries: 5})
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					_, span := kit.StartSpan(ctx, fmt.Sprintf("span-%d", i%10))
+					span.End()
+				}
+			}()
+		}
+		wg.Wait()
+
+		calls := spanCalls(t, reader)
+		var total int64
+		for _, n := range calls {
+			total += n
+		}
+		if len(calls) != 6 || total != 400 {
+			t.Errorf("Expected 5 series plus overflow covering 400 calls, got %d calls in %v", total, calls)
+		}
+	})
+
+	t.Run("UnsampledSpansRecorded", func(t *testing.T) {
+		kit, reader := newSpanMetricsKit(t, Config{})
+		_, span := kit.StartSpan(ctx, "unsampled")
+		if !span.IsRecording() || span.SpanContext().IsSampled() {
+			t.Errorf("Expected a recorded but unsampled span")
+		}
+		span.End()
+
+		if n := spanCalls(t, reader)["span.kind=internal,span.name=unsampled,status.code=unset"]; n != 1 {
+			t.Errorf("Expected the unsampled span to be counted, got %d", n)
+		}
+	})
+
+	t.Run("EnabledByConfig", func(t *testing.T) {
+		kit, err := New(Config{ServiceName: "span-metrics", ExporterType: ExporterNone, EnableMetrics: true, MetricsExporterType: ExporterNone, SpanMetrics: true})
+		if err != nil {
+			t.Fatalf("Failed to initialize OTelKit: %v", err)
+		}
+		defer kit.Shutdown(ctx)
+		if kit.spanMetrics == nil {
+			t.Error("Expected the span metrics processor to be installed")
+		}
+	})
+}
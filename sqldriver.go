@@ -0,0 +1,651 @@
+package otelkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// SQLOption configures database/sql instrumentation created by WrapDriver and OpenDB.
+type SQLOption func(*sqlConfig)
+
+// sqlConfig holds the settings applied by SQLOption values.
+//
+// Fields:
+//   - system: Value for db.system (e.g., "postgresql", "mysql", "sqlite")
+//   - namespace: Value for db.namespace (usually the database name), omitted if empty
+type sqlConfig struct {
+	system    string
+	namespace string
+}
+
+// WithDBSystem sets the db.system attribute recorded on every span.
+// OpenDB derives it from the driver name when not set.
+func WithDBSystem(system string) SQLOption {
+	return func(c *sqlConfig) {
+		c.system = system
+	}
+}
+
+// WithDBNamespace sets the db.namespace attribute (the logical database name).
+func WithDBNamespace(namespace string) SQLOption {
+	return func(c *sqlConfig) {
+		c.namespace = namespace
+	}
+}
+
+// WrapDriver instruments a database/sql driver so every connection, statement,
+// transaction and result set it produces is traced.
+//
+// Parameters:
+//   - d: The driver to wrap (e.g., &pq.Driver{}, &sqlite3.SQLiteDriver{})
+//   - opts: Optional settings (WithDBSystem, WithDBNamespace)
+//
+// Returns:
+//   - driver.Driver: An instrumented driver, usable with sql.Register or sql.OpenDB
+//
+// Each query and exec creates a client span and a db.client.operation.duration
+// measurement with:
+//   - db.system / db.system.name: From WithDBSystem (defaults to "other_sql")
+//   - db.query.text: The statement sanitized by SanitizeSQL
+//   - db.operation.name: The SQL verb (SELECT, INSERT, ...)
+//   - db.collection.name: The first table referenced, when it can be determined
+//
+// Query spans stay open until the rows are closed and record db.response.returned_rows.
+// Transactions produce db.BEGIN, db.COMMIT and db.ROLLBACK spans.
+//
+// Example:
+//   sql.Register("postgres-otel", kit.WrapDriver(&pq.Driver{}, otelkit.WithDBSystem("postgresql")))
+//   db, err := sql.Open("postgres-otel", dsn)
+func (o *OTelKit) WrapDriver(d driver.Driver, opts ...SQLOption) driver.Driver {
+	cfg := sqlConfig{system: "other_sql"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return &otelDriver{driver: d, kit: o, config: cfg}
+}
+
+// OpenDB opens a database whose driver is instrumented by WrapDriver and registers
+// connection pool metrics for it.
+//
+// Parameters:
+//   - driverName: Name of a registered database/sql driver (e.g., "postgres", "mysql")
+//   - dsn: Data source name passed to the driver
+//   - opts: Optional settings (WithDBSystem, WithDBNamespace)
+//
+// Returns:
+//   - *sql.DB: The instrumented database handle
+//   - error: Any error from looking up the driver or creating the connector
+//
+// Connection pool metrics are registered as with RegisterDBPoolMetrics.
+//
+// Example:
+//   db, err := kit.OpenDB("postgres", "postgres://localhost/app?sslmode=disable")
+//   if err != nil {
+//       log.Fatal(err)
+//   }
+//   defer db.Close()
+func (o *OTelKit) OpenDB(driverName, dsn string, opts ...SQLOption) (*sql.DB, error) {
+	// sql.Open does not connect, it only resolves the registered driver
+	probe, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := probe.Driver()
+	probe.Close()
+
+	wrapped := o.WrapDriver(d, append([]SQLOption{WithDBSystem(dbSystemFromDriverName(driverName))}, opts...)...).(*otelDriver)
+	connector, err := wrapped.OpenConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connector for driver %s: %w", driverName, err)
+	}
+
+	db := sql.OpenDB(connector)
+	if _, err := o.registerDBPoolMetrics(db, wrapped.config); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to register connection pool metrics: %w", err)
+	}
+	return db, nil
+}
+
+// dbSystemFromDriverName maps common driver registration names to db.system values.
+func dbSystemFromDriverName(name string) string {
+	switch strings.ToLower(name) {
+	case "postgres", "pgx", "pgx/v5", "postgresql", "cloudsqlpostgres":
+		return "postgresql"
+	case "mysql":
+		return "mysql"
+	case "sqlite", "sqlite3":
+		return "sqlite"
+	case "sqlserver", "mssql":
+		return "microsoft.sql_server"
+	case "oracle", "godror", "oci8":
+		return "oracle.db"
+	case "clickhouse":
+		return "clickhouse"
+	default:
+		return "other_sql"
+	}
+}
+
+// poolName identifies a connection pool in metrics.
+func (c sqlConfig) poolName() string {
+	if c.namespace != "" {
+		return c.system + "/" + c.namespace
+	}
+	return c.system
+}
+
+// otelDriver wraps a driver.Driver and instruments the connections it opens.
+type otelDriver struct {
+	driver driver.Driver
+	kit    *OTelKit
+	config sqlConfig
+}
+
+// Open implements driver.Driver.
+func (d *otelDriver) Open(name string) (driver.Conn, error) {
+	conn, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &otelConn{conn: conn, drv: d}, nil
+}
+
+// OpenConnector implements driver.DriverContext, falling back to Open for drivers
+// that don't support connectors.
+func (d *otelDriver) OpenConnector(name string) (driver.Connector, error) {
+	if dc, ok := d.driver.(driver.DriverContext); ok {
+		connector, err := dc.OpenConnector(name)
+		if err != nil {
+			return nil, err
+		}
+		return &otelConnector{connector: connector, drv: d}, nil
+	}
+	return &dsnConnector{dsn: name, drv: d}, nil
+}
+
+// otelConnector wraps a driver.Connector.
+type otelConnector struct {
+	connector driver.Connector
+	drv       *otelDriver
+}
+
+// Connect implements driver.Connector.
+func (c *otelConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &otelConn{conn: conn, drv: c.drv}, nil
+}
+
+// Driver implements driver.Connector.
+func (c *otelConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// dsnConnector adapts a driver without connector support to driver.Connector.
+type dsnConnector struct {
+	dsn string
+	drv *otelDriver
+}
+
+// Connect implements driver.Connector.
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.drv.Open(c.dsn)
+}
+
+// Driver implements driver.Connector.
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.drv
+}
+
+// dbCall tracks one traced database round trip so the span and the
+// db.client.operation.duration histogram are finished together.
+type dbCall struct {
+	ctx   context.Context
+	span  trace.Span
+	start time.Time
+	stmt  SQLStatement
+	drv   *otelDriver
+}
+
+// startCall starts a client span describing a SQL statement (or, with only
+// Operation set, a transaction boundary such as BEGIN or COMMIT).
+func (d *otelDriver) startCall(ctx context.Context, stmt SQLStatement) (context.Context, *dbCall) {
+	return d.startCallAt(ctx, stmt, time.Now())
+}
+
+// startCallAt is startCall for a round trip that began at start. It lets a call
+// that may answer driver.ErrSkip run before its span exists, so skipped fast
+// paths leave no span behind.
+func (d *otelDriver) startCallAt(ctx context.Context, stmt SQLStatement, start time.Time) (context.Context, *dbCall) {
+	spanName := "db.query"
+	if stmt.Operation != "" {
+		spanName = "db." + stmt.Operation
+	}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", d.config.system),
+		semconv.DBSystemNameKey.String(d.config.system),
+	}
+	if stmt.Sanitized != "" {
+		attrs = append(attrs, semconv.DBQueryText(stmt.Sanitized))
+	}
+	if stmt.Operation != "" {
+		attrs = append(attrs, semconv.DBOperationName(stmt.Operation))
+	}
+	if table := stmt.Table(); table != "" {
+		attrs = append(attrs, semconv.DBCollectionName(table))
+	}
+	if d.config.namespace != "" {
+		attrs = append(attrs, semconv.DBNamespace(d.config.namespace))
+	}
+
+	ctx, span := d.kit.StartSpan(ctx, spanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+		trace.WithTimestamp(start),
+	)
+	return ctx, &dbCall{ctx: ctx, span: span, start: start, stmt: stmt, drv: d}
+}
+
+// end records err on the span, records the operation duration and ends the span.
+func (c *dbCall) end(err error) {
+	if err != nil {
+		c.span.RecordError(err)
+		c.span.SetStatus(codes.Error, err.Error())
+	}
+	c.drv.kit.recordDBOperation(c.ctx, c.drv.config.system, c.stmt.Operation, c.stmt.Table(), time.Since(c.start), err)
+	c.span.End()
+}
+
+// otelConn wraps a driver.Conn.
+type otelConn struct {
+	conn driver.Conn
+	drv  *otelDriver
+}
+
+// Prepare implements driver.Conn.
+func (c *otelConn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+// PrepareContext implements driver.ConnPrepareContext.
+func (c *otelConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if pc, ok := c.conn.(driver.ConnPrepareContext); ok {
+		stmt, err = pc.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &otelStmt{stmt: stmt, parsed: parseSQL(query, c.drv.config.system), drv: c.drv}, nil
+}
+
+// Close implements driver.Conn.
+func (c *otelConn) Close() error {
+	return c.conn.Close()
+}
+
+// Begin implements driver.Conn.
+func (c *otelConn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+// BeginTx implements driver.ConnBeginTx.
+func (c *otelConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	bt, ok := c.conn.(driver.ConnBeginTx)
+	if !ok {
+		// Begin cannot honour options, so reject them as database/sql does
+		if opts.Isolation != driver.IsolationLevel(sql.LevelDefault) {
+			return nil, errors.New("sql: driver does not support non-default isolation level")
+		}
+		if opts.ReadOnly {
+			return nil, errors.New("sql: driver does not support read-only transactions")
+		}
+	}
+
+	spanCtx, call := c.drv.startCall(ctx, SQLStatement{Operation: "BEGIN"})
+
+	var tx driver.Tx
+	var err error
+	if ok {
+		tx, err = bt.BeginTx(spanCtx, opts)
+	} else {
+		//nolint:staticcheck // fallback for drivers without ConnBeginTx
+		tx, err = c.conn.Begin()
+	}
+	call.end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commit and Rollback take no context, so they are parented to the caller of BeginTx
+	return &otelTx{tx: tx, ctx: ctx, drv: c.drv}, nil
+}
+
+// ExecContext implements driver.ExecerContext.
+func (c *otelConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		// database/sql falls back to Prepare + Stmt.Exec, which is traced there
+		return nil, driver.ErrSkip
+	}
+
+	// The span starts once the driver has accepted the call: on driver.ErrSkip
+	// database/sql retries with Prepare and Stmt.Exec, which is traced there
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	_, call := c.drv.startCallAt(ctx, parseSQL(query, c.drv.config.system), start)
+	if err == nil {
+		recordRowsAffected(call.span, result)
+	}
+	call.end(err)
+	return result, err
+}
+
+// QueryContext implements driver.QueryerContext.
+func (c *otelConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	// As in ExecContext, skipped fast paths are traced by the Prepare fallback
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if errors.Is(err, driver.ErrSkip) {
+		return nil, err
+	}
+	_, call := c.drv.startCallAt(ctx, parseSQL(query, c.drv.config.system), start)
+	if err != nil {
+		call.end(err)
+		return nil, err
+	}
+	return &otelRows{rows: rows, call: call}, nil
+}
+
+// Ping implements driver.Pinger.
+func (c *otelConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession implements driver.SessionResetter.
+func (c *otelConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+// IsValid implements driver.Validator.
+func (c *otelConn) IsValid() bool {
+	if validator, ok := c.conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (c *otelConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	// ErrSkip tells database/sql to use its default conversion
+	return driver.ErrSkip
+}
+
+// otelStmt wraps a prepared driver.Stmt.
+//
+// Fields:
+//   - stmt: The underlying prepared statement
+//   - parsed: The statement parsed once at prepare time and reused for every execution
+//   - drv: The instrumented driver
+type otelStmt struct {
+	stmt   driver.Stmt
+	parsed SQLStatement
+	drv    *otelDriver
+}
+
+// Close implements driver.Stmt.
+func (s *otelStmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NumInput implements driver.Stmt.
+func (s *otelStmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+// Exec implements driver.Stmt.
+func (s *otelStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), valuesToNamedValues(args))
+}
+
+// Query implements driver.Stmt.
+func (s *otelStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), valuesToNamedValues(args))
+}
+
+// ExecContext implements driver.StmtExecContext.
+func (s *otelStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	ctx, call := s.drv.startCall(ctx, s.parsed)
+
+	var result driver.Result
+	var err error
+	if ec, ok := s.stmt.(driver.StmtExecContext); ok {
+		result, err = ec.ExecContext(ctx, args)
+	} else {
+		//nolint:staticcheck // fallback for drivers without StmtExecContext
+		result, err = s.stmt.Exec(namedValuesToValues(args))
+	}
+	if err == nil {
+		recordRowsAffected(call.span, result)
+	}
+	call.end(err)
+	return result, err
+}
+
+// QueryContext implements driver.StmtQueryContext.
+func (s *otelStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	ctx, call := s.drv.startCall(ctx, s.parsed)
+
+	var rows driver.Rows
+	var err error
+	if qc, ok := s.stmt.(driver.StmtQueryContext); ok {
+		rows, err = qc.QueryContext(ctx, args)
+	} else {
+		//nolint:staticcheck // fallback for drivers without StmtQueryContext
+		rows, err = s.stmt.Query(namedValuesToValues(args))
+	}
+	if err != nil {
+		call.end(err)
+		return nil, err
+	}
+	return &otelRows{rows: rows, call: call}, nil
+}
+
+// CheckNamedValue implements driver.NamedValueChecker.
+func (s *otelStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// otelTx wraps a driver.Tx.
+//
+// Fields:
+//   - tx: The underlying transaction
+//   - ctx: The context passed to BeginTx, used as parent for COMMIT/ROLLBACK spans
+//   - drv: The instrumented driver
+type otelTx struct {
+	tx  driver.Tx
+	ctx context.Context
+	drv *otelDriver
+}
+
+// Commit implements driver.Tx.
+func (t *otelTx) Commit() error {
+	_, call := t.drv.startCall(t.ctx, SQLStatement{Operation: "COMMIT"})
+	err := t.tx.Commit()
+	call.end(err)
+	return err
+}
+
+// Rollback implements driver.Tx.
+func (t *otelTx) Rollback() error {
+	_, call := t.drv.startCall(t.ctx, SQLStatement{Operation: "ROLLBACK"})
+	err := t.tx.Rollback()
+	call.end(err)
+	return err
+}
+
+// otelRows wraps driver.Rows and keeps the query span open until the rows are closed.
+//
+// Fields:
+//   - rows: The underlying result set
+//   - call: The query call, ended by Close
+//   - count: Number of rows read so far
+//   - err: The first iteration error other than io.EOF
+type otelRows struct {
+	rows  driver.Rows
+	call  *dbCall
+	count int64
+	err   error
+}
+
+// Columns implements driver.Rows.
+func (r *otelRows) Columns() []string {
+	return r.rows.Columns()
+}
+
+// Next implements driver.Rows.
+func (r *otelRows) Next(dest []driver.Value) error {
+	err := r.rows.Next(dest)
+	switch {
+	case err == nil:
+		r.count++
+	case err != io.EOF && r.err == nil:
+		r.err = err
+	}
+	return err
+}
+
+// Close implements driver.Rows.
+func (r *otelRows) Close() error {
+	err := r.rows.Close()
+	r.call.span.SetAttributes(semconv.DBResponseReturnedRows(int(r.count)))
+	if r.err != nil {
+		err = errors.Join(r.err, err)
+	}
+	r.call.end(err)
+	return err
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *otelRows) HasNextResultSet() bool {
+	if nrs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return nrs.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *otelRows) NextResultSet() error {
+	if nrs, ok := r.rows.(driver.RowsNextResultSet); ok {
+		return nrs.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *otelRows) ColumnTypeScanType(index int) reflect.Type {
+	if ct, ok := r.rows.(driver.RowsColumnTypeScanType); ok {
+		return ct.ColumnTypeScanType(index)
+	}
+	return reflect.TypeOf(new(any)).Elem()
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName.
+func (r *otelRows) ColumnTypeDatabaseTypeName(index int) string {
+	if ct, ok := r.rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return ct.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength.
+func (r *otelRows) ColumnTypeLength(index int) (int64, bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypeLength); ok {
+		return ct.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable.
+func (r *otelRows) ColumnTypeNullable(index int) (bool, bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypeNullable); ok {
+		return ct.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale.
+func (r *otelRows) ColumnTypePrecisionScale(index int) (int64, int64, bool) {
+	if ct, ok := r.rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return ct.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
+
+// recordRowsAffected adds the affected row count to the span when the driver reports it.
+func recordRowsAffected(span trace.Span, result driver.Result) {
+	if result == nil {
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil {
+		span.SetAttributes(attribute.Int64("db.response.affected_rows", n))
+	}
+}
+
+// valuesToNamedValues converts positional arguments to named values.
+func valuesToNamedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+// namedValuesToValues converts named values back to positional arguments for
+// drivers that only implement the pre-context interfaces.
+func namedValuesToValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
@@ -0,0 +1,367 @@
+package otelkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// fakeDriver is an in-memory database/sql driver. Every query returns the rows
+// stored for the table it names; exec statements append or fail on demand.
+type fakeDriver struct {
+	mu     sync.Mutex
+	tables map[string][][]driver.Value
+}
+
+var (
+	registerFakeDriver sync.Once
+	fakeDB             = newFakeDriver()
+)
+
+func newFakeDriver() *fakeDriver {
+	return &fakeDriver{tables: map[string][][]driver.Value{
+		"users": {{int64(1), "alice"}, {int64(2), "bob"}},
+	}}
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{drv: d}, nil
+}
+
+type fakeConn struct {
+	drv *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+// ExecContext returns driver.ErrSkip for queries naming "skip", sending
+// database/sql to Prepare and Stmt.Exec as drivers do for unsupported fast paths.
+func (c *fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "skip") {
+		return nil, driver.ErrSkip
+	}
+	return c.exec(query, args)
+}
+
+func (c *fakeConn) exec(query string, args []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, "fail") {
+		return nil, errors.New("fake: exec failed")
+	}
+	table := ParseSQL(query).Table()
+	c.drv.mu.Lock()
+	defer c.drv.mu.Unlock()
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.drv.tables[table] = append(c.drv.tables[table], values)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	table := ParseSQL(query).Table()
+	c.drv.mu.Lock()
+	defer c.drv.mu.Unlock()
+	return &fakeRows{rows: append([][]driver.Value(nil), c.drv.tables[table]...)}, nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.conn.exec(s.query, valuesToNamedValues(args))
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.conn.QueryContext(context.Background(), s.query, valuesToNamedValues(args))
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestSQLDriverInstrumentation(t *testing.T) {
+	registerFakeDriver.Do(func() {
+		sql.Register("otelkit-fake", fakeDB)
+	})
+	fakeDB.mu.Lock()
+	fakeDB.tables = newFakeDriver().tables
+	fakeDB.mu.Unlock()
+
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "sql-test"})
+	reader := sdkmetric.NewManualReader()
+	kit.meter = sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("otelkit-test")
+	if err := kit.initMetricsInstruments(kit.meter); err != nil {
+		t.Fatalf("initMetricsInstruments failed: %v", err)
+	}
+
+	db, err := kit.OpenDB("otelkit-fake", "memory", WithDBSystem("sqlite"))
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	t.Run("Query", func(t *testing.T) {
+		recorder.Reset()
+
+		rows, err := db.QueryContext(ctx, "SELECT id, name FROM users WHERE name = 'alice' AND id > 10")
+		if err != nil {
+			t.Fatalf("Query failed: %v", err)
+		}
+		count := 0
+		for rows.Next() {
+			count++
+		}
+		rows.Close()
+		if count != 2 {
+			t.Errorf("Expected 2 rows, got %d", count)
+		}
+
+		span := findSpan(t, recorder.Ended(), "db.SELECT")
+		if span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("Expected client span, got %v", span.SpanKind())
+		}
+		attrs := attributeMap(span.Attributes())
+		if got := attrs["db.query.text"]; got != "SELECT id, name FROM users WHERE name = ? AND id > ?" {
+			t.Errorf("Query text not sanitized: %q", got)
+		}
+		if attrs["db.system"] != "sqlite" || attrs["db.operation.name"] != "SELECT" || attrs["db.collection.name"] != "users" {
+			t.Errorf("Unexpected attributes: %v", attrs)
+		}
+		if attrs["db.response.returned_rows"] != "2" {
+			t.Errorf("Expected returned_rows=2, got %q", attrs["db.response.returned_rows"])
+		}
+	})
+
+	t.Run("ExecError", func(t *testing.T) {
+		recorder.Reset()
+
+		if _, err := db.ExecContext(ctx, "INSERT INTO fail (id) VALUES (?)", 3); err == nil {
+			t.Fatal("Expected exec error")
+		}
+		span := findSpan(t, recorder.Ended(), "db.INSERT")
+		if span.Status().Description != "fake: exec failed" {
+			t.Errorf("Expected error status, got %v", span.Status())
+		}
+	})
+
+	t.Run("ExecSkip", func(t *testing.T) {
+		recorder.Reset()
+
+		if _, err := db.ExecContext(ctx, "INSERT INTO skip (id) VALUES (?)", 4); err != nil {
+			t.Fatalf("Exec failed: %v", err)
+		}
+		var spans int
+		for _, span := range recorder.Ended() {
+			if span.Name() != "db.INSERT" {
+				continue
+			}
+			spans++
+			if span.Status().Code == codes.Error {
+				t.Errorf("Expected no error status, got %v", span.Status())
+			}
+		}
+		if spans != 1 {
+			t.Errorf("Expected only the fallback to be traced, got %d spans", spans)
+		}
+	})
+
+	t.Run("BeginTxOptionsWithoutConnBeginTx", func(t *testing.T) {
+		for _, opts := range []*sql.TxOptions{
+			{ReadOnly: true},
+			{Isolation: sql.LevelSerializable},
+		} {
+			tx, err := db.BeginTx(ctx, opts)
+			if err == nil {
+				tx.Rollback()
+				t.Errorf("Expected %+v to be rejected by a driver without ConnBeginTx", *opts)
+			}
+		}
+	})
+
+	t.Run("Transaction", func(t *testing.T) {
+		recorder.Reset()
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatalf("BeginTx failed: %v", err)
+		}
+		stmt, err := tx.PrepareContext(ctx, "UPDATE users SET name = ? WHERE id = ?")
+		if err != nil {
+			t.Fatalf("Prepare failed: %v", err)
+		}
+		if _, err := stmt.ExecContext(ctx, "carol", 1); err != nil {
+			t.Fatalf("Stmt exec failed: %v", err)
+		}
+		stmt.Close()
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("Commit failed: %v", err)
+		}
+
+		for _, name := range []string{"db.BEGIN", "db.UPDATE", "db.COMMIT"} {
+			findSpan(t, recorder.Ended(), name)
+		}
+	})
+
+	t.Run("PoolMetrics", func(t *testing.T) {
+		found := map[string]bool{}
+		for _, m := range collectMetrics(t, reader) {
+			found[m.Name] = true
+		}
+		for _, name := range []string{"db.client.connection.count", "db.client.connection.max", "db.client.connection.wait_count", "db.client.connection.wait_time"} {
+			if !found[name] {
+				t.Errorf("Missing pool metric %s", name)
+			}
+		}
+	})
+
+	t.Run("OperationDuration", func(t *testing.T) {
+		counts := map[string]uint64{}
+		for _, m := range collectMetrics(t, reader) {
+			if m.Name != "db.client.operation.duration" {
+				continue
+			}
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				counts[dp.Attributes.Encoded(attribute.DefaultEncoder())] += dp.Count
+			}
+		}
+
+		expected := map[string]uint64{
+			"db.collection.name=users,db.operation.name=SELECT,db.system.name=sqlite":                               1,
+			"db.collection.name=fail,db.operation.name=INSERT,db.system.name=sqlite,error.type=*errors.errorString": 1,
+			"db.collection.name=skip,db.operation.name=INSERT,db.system.name=sqlite":                                1,
+			"db.operation.name=BEGIN,db.system.name=sqlite":                                                         1,
+			"db.collection.name=users,db.operation.name=UPDATE,db.system.name=sqlite":                               1,
+			"db.operation.name=COMMIT,db.system.name=sqlite":                                                        1,
+		}
+		for key, want := range expected {
+			if counts[key] != want {
+				t.Errorf("Expected %d measurement(s) for {%s}, got %d (all: %v)", want, key, counts[key], counts)
+			}
+		}
+	})
+}
+
+// collectMetrics collects all metrics from reader or fails the test.
+func collectMetrics(t *testing.T, reader *sdkmetric.ManualReader) []metricdata.Metrics {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	var metrics []metricdata.Metrics
+	for _, sm := range rm.ScopeMetrics {
+		metrics = append(metrics, sm.Metrics...)
+	}
+	return metrics
+}
+
+// findSpan returns the ended span with the given name or fails the test.
+func findSpan(t *testing.T, spans []sdktrace.ReadOnlySpan, name string) sdktrace.ReadOnlySpan {
+	t.Helper()
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("Span %s not found", name)
+	return nil
+}
+
+func TestDBErrorType(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected string
+	}{
+		{context.DeadlineExceeded, "timeout"},
+		{&net.OpError{Op: "read", Err: os.ErrDeadlineExceeded}, "timeout"},
+		{fmt.Errorf("query: %w", context.Canceled), "canceled"},
+		{sql.ErrNoRows, "no_rows"},
+		{driver.ErrBadConn, "connection"},
+		{sql.ErrConnDone, "connection"},
+		{sql.ErrTxDone, "tx_done"},
+		{&PanicError{Value: "boom"}, "panic"},
+		{errors.New("syntax error"), "*errors.errorString"},
+	}
+
+	for _, tt := range tests {
+		if got := dbErrorType(tt.err); got != tt.expected {
+			t.Errorf("dbErrorType(%v): expected %q, got %q", tt.err, tt.expected, got)
+		}
+	}
+}
+
+func TestDatabaseOperationDuration(t *testing.T) {
+	kit, _ := newRecordingKit(t, Config{ServiceName: "sql-test"})
+	reader := sdkmetric.NewManualReader()
+	if err := kit.initMetricsInstruments(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("otelkit-test")); err != nil {
+		t.Fatalf("initMetricsInstruments failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	kit.DatabaseOperationWithOptions(ctx, "SELECT", "orders", func(ctx context.Context) error {
+		return ctx.Err()
+	}, WithAttributes(attribute.String("db.system", "postgresql")))
+
+	for _, m := range collectMetrics(t, reader) {
+		if m.Name != "db.client.operation.duration" {
+			continue
+		}
+		points := m.Data.(metricdata.Histogram[float64]).DataPoints
+		if len(points) != 1 {
+			t.Fatalf("Expected 1 data point, got %d", len(points))
+		}
+		got := points[0].Attributes.Encoded(attribute.DefaultEncoder())
+		if expected := "db.collection.name=orders,db.operation.name=SELECT,db.system.name=postgresql,error.type=canceled"; got != expected {
+			t.Errorf("Expected attributes %q, got %q", expected, got)
+		}
+		return
+	}
+	t.Fatal("db.client.operation.duration not recorded")
+}
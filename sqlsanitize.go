@@ -0,0 +1,630 @@
+package otelkit
+
+import (
+	"strings"
+)
+
+// SQLStatement describes a SQL statement after sanitization.
+//
+// Fields:
+//   - Operation: The statement verb in upper case (SELECT, INSERT, UPDATE, DELETE, CREATE, ...)
+//   - Tables: Tables referenced by the statement, in order of first appearance
+//   - Sanitized: The statement with literals replaced by "?", IN-lists collapsed,
+//     comments removed and whitespace normalized
+type SQLStatement struct {
+	Operation string
+	Tables    []string
+	Sanitized string
+}
+
+// Table returns the first table referenced by the statement, or "" if none was found.
+// This is the value recorded as db.collection.name.
+func (s SQLStatement) Table() string {
+	if len(s.Tables) == 0 {
+		return ""
+	}
+	return s.Tables[0]
+}
+
+// SanitizeSQL replaces literal values in a SQL statement with "?" so the query
+// can be recorded in telemetry without leaking data.
+//
+// Parameters:
+//   - query: The SQL statement to sanitize
+//
+// Returns:
+//   - string: The sanitized statement
+//
+// Sanitization:
+//   - String literals ('...', E'...', N'...', X'...', $$...$$, $tag$...$tag$) become ?
+//   - Numeric literals (42, 3.14, 1e10, 0xFF) become ?
+//   - IN-lists of literals or placeholders collapse to IN (?)
+//   - Comments are removed and runs of whitespace become a single space
+//   - Identifiers, quoted identifiers ("x", `x`, [x]) and placeholders ($1, ?, :name, @p1) are kept
+//
+// Double-quoted text is treated as an identifier (ANSI SQL, PostgreSQL, SQLite,
+// Oracle). MySQL reads it as a string, so statements traced with the mysql or
+// mariadb db system (OpenDB, WrapDriver, DatabaseOperation) mask it as well, and
+// honour backslash escapes inside quotes. Without a db system a literal closed by
+// \' could be read either way, so everything from it to the end is masked.
+//
+// Example:
+//   otelkit.SanitizeSQL("SELECT * FROM users WHERE email = 'a@b.c' AND id IN (1, 2, 3)")
+//   // "SELECT * FROM users WHERE email = ? AND id IN (?)"
+func SanitizeSQL(query string) string {
+	return ParseSQL(query).Sanitized
+}
+
+// ParseSQL sanitizes a SQL statement and extracts its operation and table names.
+//
+// Parameters:
+//   - query: The SQL statement to parse
+//
+// Returns:
+//   - SQLStatement: Operation, tables and sanitized text
+//
+// The parser is a tolerant tokenizer rather than a full grammar, so it never
+// fails: malformed input yields a best-effort result. Tables are taken from
+// FROM, JOIN, INTO, UPDATE, TABLE, TRUNCATE and USING clauses (including
+// subqueries), while common table expression names are excluded.
+//
+// Example:
+//   stmt := otelkit.ParseSQL("WITH recent AS (SELECT * FROM orders) SELECT * FROM recent JOIN users u ON u.id = recent.user_id")
+//   // stmt.Operation == "SELECT", stmt.Tables == []string{"orders", "users"}
+func ParseSQL(query string) SQLStatement {
+	return parseSQL(query, "")
+}
+
+// parseSQL is ParseSQL for the db system (a db.system.name value) that runs
+// the query: MySQL and MariaDB read double-quoted text as a string literal and
+// backslashes inside quotes as escapes.
+func parseSQL(query, system string) SQLStatement {
+	tokens := lexSQL(query, system == "mysql" || system == "mariadb")
+	return SQLStatement{
+		Operation: sqlOperation(tokens),
+		Tables:    sqlTables(tokens),
+		Sanitized: sanitizeTokens(tokens),
+	}
+}
+
+// sqlTokenKind classifies lexed SQL tokens.
+type sqlTokenKind int
+
+const (
+	tokenIdent       sqlTokenKind = iota // unquoted identifier or keyword
+	tokenQuotedIdent                     // "x", `x` or [x]
+	tokenLiteral                         // string or numeric literal
+	tokenPlaceholder                     // ?, $1, :name, @p1
+	tokenPunct                           // operators and punctuation
+)
+
+// sqlToken is a single lexed token.
+//
+// Fields:
+//   - kind: The token classification
+//   - text: The raw token text
+//   - space: Whether whitespace or a comment preceded the token
+type sqlToken struct {
+	kind  sqlTokenKind
+	text  string
+	space bool
+}
+
+// upper returns the token text in upper case, for keyword comparisons.
+func (t sqlToken) upper() string {
+	return strings.ToUpper(t.text)
+}
+
+// isKeyword reports whether the token is the unquoted keyword kw (upper case).
+func (t sqlToken) isKeyword(kw string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, kw)
+}
+
+// lexSQL splits a query into tokens, dropping whitespace and comments.
+// With mysql, "..." is a string literal rather than an identifier and backslashes
+// escape the next byte inside string literals.
+func lexSQL(query string, mysql bool) []sqlToken {
+	var tokens []sqlToken
+	space := false
+	literalEscapes := escapesAmbiguous
+	if mysql {
+		literalEscapes = escapesBackslash
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		start := i
+
+		switch {
+		case isSpace(c):
+			i++
+			space = true
+			continue
+
+		case c == '-' && i+1 < len(query) && query[i+1] == '-':
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+			space = true
+			continue
+
+		case c == '/' && i+1 < len(query) && query[i+1] == '*':
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				i = len(query)
+			} else {
+				i += end + 4
+			}
+			space = true
+			continue
+
+		case c == '\'' || (c == '"' && mysql):
+			i = skipQuoted(query, i, c, literalEscapes)
+			tokens = append(tokens, sqlToken{kind: tokenLiteral, text: query[start:i], space: space})
+
+		case c == '"' || c == '`':
+			i = skipQuoted(query, i, c, escapesNone)
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdent, text: query[start:i], space: space})
+
+		case c == '[' && isBracketIdent(query[i:]):
+			i += strings.IndexByte(query[i:], ']') + 1
+			tokens = append(tokens, sqlToken{kind: tokenQuotedIdent, text: query[start:i], space: space})
+
+		case c == '$':
+			i = lexDollar(query, i)
+			kind := tokenPunct
+			if i-start > 1 {
+				if isDigit(query[start+1]) {
+					kind = tokenPlaceholder
+				} else {
+					kind = tokenLiteral
+				}
+			}
+			tokens = append(tokens, sqlToken{kind: kind, text: query[start:i], space: space})
+
+		case c == '?':
+			i++
+			tokens = append(tokens, sqlToken{kind: tokenPlaceholder, text: "?", space: space})
+
+		case (c == ':' || c == '@') && i+1 < len(query) && isIdentStart(query[i+1]) && !(c == ':' && i > 0 && query[i-1] == ':'):
+			i++
+			for i < len(query) && isIdentByte(query[i]) {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenPlaceholder, text: query[start:i], space: space})
+
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			i = lexNumber(query, i)
+			tokens = append(tokens, sqlToken{kind: tokenLiteral, text: query[start:i], space: space})
+
+		case isIdentStart(c):
+			for i < len(query) && isIdentByte(query[i]) {
+				i++
+			}
+			// Prefixed strings: E'...', N'...', X'...', B'...'
+			if i-start == 1 && i < len(query) && query[i] == '\'' && strings.ContainsRune("eEnNxXbB", rune(c)) {
+				escapes := literalEscapes
+				if c == 'e' || c == 'E' {
+					escapes = escapesBackslash
+				}
+				i = skipQuoted(query, i, '\'', escapes)
+				tokens = append(tokens, sqlToken{kind: tokenLiteral, text: query[start:i], space: space})
+				break
+			}
+			tokens = append(tokens, sqlToken{kind: tokenIdent, text: query[start:i], space: space})
+
+		default:
+			// Non-ASCII bytes are kept with the preceding run so UTF-8 stays intact
+			i++
+			for c >= 0x80 && i < len(query) && query[i] >= 0x80 {
+				i++
+			}
+			tokens = append(tokens, sqlToken{kind: tokenPunct, text: query[start:i], space: space})
+		}
+		space = false
+	}
+	return tokens
+}
+
+// quoteEscapes selects how skipQuoted reads a backslash inside a quoted run.
+type quoteEscapes int
+
+const (
+	// escapesNone treats a backslash as an ordinary byte (quoted identifiers).
+	escapesNone quoteEscapes = iota
+	// escapesBackslash makes a backslash escape the next byte (MySQL strings, E'...').
+	escapesBackslash
+	// escapesAmbiguous is for strings of an unknown dialect: a quote preceded by a
+	// backslash may or may not close the run, so the run extends to the end.
+	escapesAmbiguous
+)
+
+// skipQuoted returns the index just past the quoted run starting at i.
+// Doubled quotes are treated as part of the run, and an unterminated run extends
+// to the end of the query, so an unbalanced quote masks the rest of the statement.
+// With escapesAmbiguous, 'O\'Brien x@y.z' and 'C:\' AND b = 'x' both mask
+// everything after the first quote: reading them with the wrong dialect would
+// expose the text after the backslash.
+func skipQuoted(query string, i int, quote byte, escapes quoteEscapes) int {
+	for i++; i < len(query); i++ {
+		switch {
+		case query[i] == '\\' && escapes == escapesBackslash:
+			i++
+		case query[i] == quote:
+			if escapes == escapesAmbiguous && query[i-1] == '\\' {
+				return len(query)
+			}
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// isBracketIdent reports whether s starts with a SQL Server style [identifier].
+// Brackets containing quotes or placeholders (e.g. ARRAY['a'], arr[?]) are left
+// to the regular lexer so the literals inside are still sanitized.
+func isBracketIdent(s string) bool {
+	end := strings.IndexByte(s, ']')
+	return end > 0 && !strings.ContainsAny(s[1:end], "'\"`[?")
+}
+
+// lexDollar handles "$": positional placeholders ($1), dollar-quoted strings
+// ($$...$$, $tag$...$tag$) and a bare "$".
+func lexDollar(query string, i int) int {
+	j := i + 1
+	if j < len(query) && isDigit(query[j]) {
+		for j < len(query) && isDigit(query[j]) {
+			j++
+		}
+		return j
+	}
+
+	for j < len(query) && isIdentByte(query[j]) && query[j] != '$' {
+		j++
+	}
+	if j >= len(query) || query[j] != '$' {
+		return i + 1
+	}
+
+	tag := query[i : j+1]
+	if end := strings.Index(query[j+1:], tag); end >= 0 {
+		return j + 1 + end + len(tag)
+	}
+	return len(query)
+}
+
+// lexNumber returns the index just past the numeric literal starting at i.
+func lexNumber(query string, i int) int {
+	if query[i] == '0' && i+1 < len(query) && (query[i+1] == 'x' || query[i+1] == 'X') {
+		i += 2
+		for i < len(query) && isHexDigit(query[i]) {
+			i++
+		}
+		return i
+	}
+
+	for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+		i++
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < len(query) && isDigit(query[j]) {
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			i = j
+		}
+	}
+	return i
+}
+
+// sanitizeTokens rebuilds the statement with literals replaced and IN-lists collapsed.
+func sanitizeTokens(tokens []sqlToken) string {
+	var b strings.Builder
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		if b.Len() > 0 && tok.space {
+			b.WriteByte(' ')
+		}
+
+		if tok.kind == tokenLiteral {
+			b.WriteByte('?')
+			continue
+		}
+		b.WriteString(tok.text)
+
+		if tok.isKeyword("IN") {
+			if end, ok := valueListEnd(tokens, i+1); ok {
+				if tokens[i+1].space {
+					b.WriteByte(' ')
+				}
+				b.WriteString("(?)")
+				i = end
+			}
+		}
+	}
+	return b.String()
+}
+
+// valueListEnd reports whether tokens[start:] begins with a parenthesised list of
+// literals and placeholders, returning the index of the closing parenthesis.
+func valueListEnd(tokens []sqlToken, start int) (int, bool) {
+	if start >= len(tokens) || tokens[start].text != "(" {
+		return 0, false
+	}
+	expectValue := true
+	for i := start + 1; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case expectValue && (tok.kind == tokenLiteral || tok.kind == tokenPlaceholder):
+			expectValue = false
+		case expectValue && tok.text == "-" && i+1 < len(tokens) && tokens[i+1].kind == tokenLiteral:
+			// negative number
+		case !expectValue && tok.text == ",":
+			expectValue = true
+		case !expectValue && tok.text == ")":
+			return i, true
+		default:
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// sqlVerbs are the statement verbs recognised as the main operation of a WITH query.
+var sqlVerbs = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true, "REPLACE": true,
+}
+
+// sqlOperation returns the statement verb, looking past WITH clauses and leading parentheses.
+func sqlOperation(tokens []sqlToken) string {
+	for i, tok := range tokens {
+		if tok.text == "(" {
+			continue
+		}
+		if tok.kind != tokenIdent {
+			return ""
+		}
+		if tok.upper() != "WITH" {
+			return tok.upper()
+		}
+
+		// The main statement is the first verb at depth 0 after the CTE definitions
+		depth := 0
+		for _, t := range tokens[i+1:] {
+			switch {
+			case t.text == "(":
+				depth++
+			case t.text == ")":
+				depth--
+			case depth == 0 && t.kind == tokenIdent && sqlVerbs[t.upper()]:
+				return t.upper()
+			}
+		}
+		return "WITH"
+	}
+	return ""
+}
+
+// sqlClauseKeywords end a table reference list.
+var sqlClauseKeywords = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true,
+	"CROSS": true, "NATURAL": true, "ON": true, "USING": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "FETCH": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"MINUS": true, "SET": true, "VALUES": true, "VALUE": true, "SELECT": true, "RETURNING": true,
+	"WINDOW": true, "FOR": true, "AS": true, "DEFAULT": true, "OUTPUT": true, "WITH": true,
+	"LATERAL": true, "PARTITION": true, "STRAIGHT_JOIN": true, "TABLESAMPLE": true,
+}
+
+// sqlTableModifiers may appear between a table keyword and the table name.
+var sqlTableModifiers = map[string]bool{
+	"ONLY": true, "IF": true, "NOT": true, "EXISTS": true, "LOW_PRIORITY": true, "IGNORE": true,
+	"DELAYED": true, "HIGH_PRIORITY": true, "TEMPORARY": true, "TEMP": true, "UNLOGGED": true, "TABLE": true,
+}
+
+// sqlTables extracts table names from FROM, JOIN, INTO, UPDATE, TABLE, TRUNCATE
+// and USING clauses.
+func sqlTables(tokens []sqlToken) []string {
+	ctes := sqlCTENames(tokens)
+	seen := map[string]bool{}
+	var tables []string
+
+	add := func(name string) {
+		key := strings.ToLower(name)
+		if name == "" || seen[key] || ctes[key] {
+			return
+		}
+		seen[key] = true
+		tables = append(tables, name)
+	}
+
+	// subquery tracks, per open parenthesis, whether it starts a subquery; FROM
+	// inside a function call (EXTRACT(YEAR FROM x)) is not a table reference
+	var subquery []bool
+	inSubquery := func() bool {
+		return len(subquery) == 0 || subquery[len(subquery)-1]
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok.text == "(":
+			next := i + 1 < len(tokens) && (tokens[i+1].isKeyword("SELECT") || tokens[i+1].isKeyword("WITH"))
+			subquery = append(subquery, next)
+			continue
+		case tok.text == ")":
+			if len(subquery) > 0 {
+				subquery = subquery[:len(subquery)-1]
+			}
+			continue
+		case tok.kind != tokenIdent:
+			continue
+		}
+
+		switch tok.upper() {
+		case "FROM":
+			if !inSubquery() {
+				continue
+			}
+			// FROM a, b AS x, c
+			for j := i + 1; ; {
+				name, next := readTableName(tokens, j)
+				add(name)
+				next = skipAlias(tokens, next)
+				if name == "" || next >= len(tokens) || tokens[next].text != "," {
+					break
+				}
+				j = next + 1
+			}
+		case "UPDATE":
+			// Not a table in FOR UPDATE, ON DUPLICATE KEY UPDATE or DO UPDATE
+			if i > 0 && (tokens[i-1].isKeyword("FOR") || tokens[i-1].isKeyword("KEY") || tokens[i-1].isKeyword("DO")) {
+				continue
+			}
+			name, _ := readTableName(tokens, i+1)
+			add(name)
+		case "JOIN", "INTO", "TABLE", "TRUNCATE", "USING":
+			name, _ := readTableName(tokens, i+1)
+			add(name)
+		}
+	}
+	return tables
+}
+
+// readTableName reads a possibly qualified table name (schema.table, "s"."t")
+// starting at index i, skipping modifiers such as ONLY or IF NOT EXISTS.
+// It returns "" when a subquery or keyword follows instead.
+func readTableName(tokens []sqlToken, i int) (string, int) {
+	for i < len(tokens) && tokens[i].kind == tokenIdent && sqlTableModifiers[tokens[i].upper()] {
+		i++
+	}
+
+	var parts []string
+	for i < len(tokens) {
+		tok := tokens[i]
+		switch {
+		case tok.kind == tokenQuotedIdent:
+			parts = append(parts, unquoteIdent(tok.text))
+		case tok.kind == tokenIdent && !sqlClauseKeywords[tok.upper()]:
+			parts = append(parts, tok.text)
+		default:
+			return strings.Join(parts, "."), i
+		}
+		i++
+		if i >= len(tokens) || tokens[i].text != "." || tokens[i].space {
+			break
+		}
+		i++
+	}
+	return strings.Join(parts, "."), i
+}
+
+// skipAlias skips an optional "[AS] alias" after a table reference.
+func skipAlias(tokens []sqlToken, i int) int {
+	if i < len(tokens) && tokens[i].isKeyword("AS") {
+		i++
+	}
+	if i < len(tokens) && (tokens[i].kind == tokenQuotedIdent || (tokens[i].kind == tokenIdent && !sqlClauseKeywords[tokens[i].upper()])) {
+		i++
+	}
+	return i
+}
+
+// sqlCTENames returns the (lower-cased) names defined by a leading WITH clause.
+func sqlCTENames(tokens []sqlToken) map[string]bool {
+	names := map[string]bool{}
+	i := 0
+	for i < len(tokens) && tokens[i].text == "(" {
+		i++
+	}
+	if i >= len(tokens) || !tokens[i].isKeyword("WITH") {
+		return names
+	}
+	i++
+	if i < len(tokens) && tokens[i].isKeyword("RECURSIVE") {
+		i++
+	}
+
+	for i < len(tokens) {
+		if tokens[i].kind != tokenIdent && tokens[i].kind != tokenQuotedIdent {
+			break
+		}
+		names[strings.ToLower(unquoteIdent(tokens[i].text))] = true
+
+		// Skip an optional column list, then AS [NOT] [MATERIALIZED] ( ... )
+		i = skipParens(tokens, i+1)
+		for i < len(tokens) && tokens[i].kind == tokenIdent {
+			i++
+		}
+		i = skipParens(tokens, i)
+		if i >= len(tokens) || tokens[i].text != "," {
+			break
+		}
+		i++
+	}
+	return names
+}
+
+// skipParens skips a balanced parenthesised group starting at i, if there is one.
+func skipParens(tokens []sqlToken, i int) int {
+	if i >= len(tokens) || tokens[i].text != "(" {
+		return i
+	}
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i].text {
+		case "(":
+			depth++
+		case ")":
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// unquoteIdent strips identifier quotes ("x", `x`, [x]).
+func unquoteIdent(s string) string {
+	if len(s) >= 2 {
+		switch {
+		case s[0] == '"' && s[len(s)-1] == '"', s[0] == '`' && s[len(s)-1] == '`', s[0] == '[' && s[len(s)-1] == ']':
+			return s[1 : len(s)-1]
+		}
+	}
+	return strings.Trim(s, "\"`[]")
+}
+
+// isSpace reports whether c is ASCII whitespace.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+// isDigit reports whether c is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isHexDigit reports whether c is an ASCII hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// isIdentStart reports whether c can start an unquoted SQL identifier.
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isIdentByte reports whether c can appear in an unquoted SQL identifier.
+func isIdentByte(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '$'
+}
@@ -0,0 +1,263 @@
+package otelkit
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+func TestParseSQL(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		operation string
+		tables    []string
+		sanitized string
+	}{
+		{
+			name:      "SelectWithLiterals",
+			query:     "SELECT id, name FROM users WHERE email = 'jane@example.com' AND age > 42",
+			operation: "SELECT",
+			tables:    []string{"users"},
+			sanitized: "SELECT id, name FROM users WHERE email = ? AND age > ?",
+		},
+		{
+			name:      "InListCollapsed",
+			query:     "select * from orders where id in (1, 2, -3,4) and status IN ('a', 'b')",
+			operation: "SELECT",
+			tables:    []string{"orders"},
+			sanitized: "select * from orders where id in (?) and status IN (?)",
+		},
+		{
+			name:      "InSubqueryKept",
+			query:     "SELECT * FROM a WHERE id IN (SELECT a_id FROM b WHERE x = 1)",
+			operation: "SELECT",
+			tables:    []string{"a", "b"},
+			sanitized: "SELECT * FROM a WHERE id IN (SELECT a_id FROM b WHERE x = ?)",
+		},
+		{
+			name:      "InsertPlaceholders",
+			query:     "INSERT INTO public.users (name, email) VALUES ($1, $2)",
+			operation: "INSERT",
+			tables:    []string{"public.users"},
+			sanitized: "INSERT INTO public.users (name, email) VALUES ($1, $2)",
+		},
+		{
+			name:      "UpdateMySQL",
+			query:     "UPDATE `shop`.`items` SET price = 9.99, note = 'it''s' WHERE sku = 0xFF",
+			operation: "UPDATE",
+			tables:    []string{"shop.items"},
+			sanitized: "UPDATE `shop`.`items` SET price = ?, note = ? WHERE sku = ?",
+		},
+		{
+			name:      "DeleteSQLServer",
+			query:     "DELETE FROM [dbo].[sessions] WHERE expires < @p1",
+			operation: "DELETE",
+			tables:    []string{"dbo.sessions"},
+			sanitized: "DELETE FROM [dbo].[sessions] WHERE expires < @p1",
+		},
+		{
+			name:      "JoinsAndAliases",
+			query:     "SELECT u.id FROM users u JOIN orders AS o ON o.user_id = u.id LEFT JOIN \"Payments\" p ON p.order_id = o.id, audit",
+			operation: "SELECT",
+			tables:    []string{"users", "orders", "Payments"},
+			sanitized: "SELECT u.id FROM users u JOIN orders AS o ON o.user_id = u.id LEFT JOIN \"Payments\" p ON p.order_id = o.id, audit",
+		},
+		{
+			name:      "CommaJoin",
+			query:     "SELECT * FROM a x, b y WHERE x.id = y.id",
+			operation: "SELECT",
+			tables:    []string{"a", "b"},
+			sanitized: "SELECT * FROM a x, b y WHERE x.id = y.id",
+		},
+		{
+			name:      "CTE",
+			query:     "WITH recent AS (SELECT * FROM orders WHERE created > now() - interval '1 day') SELECT * FROM recent JOIN users ON users.id = recent.user_id",
+			operation: "SELECT",
+			tables:    []string{"orders", "users"},
+			sanitized: "WITH recent AS (SELECT * FROM orders WHERE created > now() - interval ?) SELECT * FROM recent JOIN users ON users.id = recent.user_id",
+		},
+		{
+			name:      "CommentsAndWhitespace",
+			query:     "SELECT /* user 'x' */ *\n\tFROM users -- where name = 'bob'\nWHERE id = 7",
+			operation: "SELECT",
+			tables:    []string{"users"},
+			sanitized: "SELECT * FROM users WHERE id = ?",
+		},
+		{
+			name:      "PostgresSpecific",
+			query:     "SELECT data::jsonb, $tag$secret$tag$, E'esc\\n', ARRAY['a','b'] FROM ONLY events WHERE ts > :since",
+			operation: "SELECT",
+			tables:    []string{"events"},
+			sanitized: "SELECT data::jsonb, ?, ?, ARRAY[?,?] FROM ONLY events WHERE ts > :since",
+		},
+		{
+			name:      "FunctionFromIsNotTable",
+			query:     "SELECT EXTRACT(YEAR FROM created_at) FROM invoices FOR UPDATE SKIP LOCKED",
+			operation: "SELECT",
+			tables:    []string{"invoices"},
+			sanitized: "SELECT EXTRACT(YEAR FROM created_at) FROM invoices FOR UPDATE SKIP LOCKED",
+		},
+		{
+			name:      "Upsert",
+			query:     "INSERT INTO counters (k, v) VALUES ('a', 1) ON DUPLICATE KEY UPDATE v = v + 1",
+			operation: "INSERT",
+			tables:    []string{"counters"},
+			sanitized: "INSERT INTO counters (k, v) VALUES (?, ?) ON DUPLICATE KEY UPDATE v = v + ?",
+		},
+		{
+			name:      "DDL",
+			query:     "CREATE TABLE IF NOT EXISTS audit_log (id bigint)",
+			operation: "CREATE",
+			tables:    []string{"audit_log"},
+			sanitized: "CREATE TABLE IF NOT EXISTS audit_log (id bigint)",
+		},
+		{
+			name:      "UnterminatedString",
+			query:     "SELECT * FROM t WHERE name = 'oops",
+			operation: "SELECT",
+			tables:    []string{"t"},
+			sanitized: "SELECT * FROM t WHERE name = ?",
+		},
+		{
+			name:  "Empty",
+			query: "   ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt := ParseSQL(tt.query)
+			if stmt.Operation != tt.operation {
+				t.Errorf("Operation: expected %q, got %q", tt.operation, stmt.Operation)
+			}
+			if !reflect.DeepEqual(stmt.Tables, tt.tables) {
+				t.Errorf("Tables: expected %q, got %q", tt.tables, stmt.Tables)
+			}
+			if stmt.Sanitized != tt.sanitized {
+				t.Errorf("Sanitized:\n expected %q\n got      %q", tt.sanitized, stmt.Sanitized)
+			}
+		})
+	}
+}
+
+func TestParseSQLDoubleQuotes(t *testing.T) {
+	query := `SELECT "name" FROM users WHERE email = "bob@example.com"`
+	tests := []struct {
+		system    string
+		sanitized string
+	}{
+		{"mysql", "SELECT ? FROM users WHERE email = ?"},
+		{"mariadb", "SELECT ? FROM users WHERE email = ?"},
+		{"postgresql", `SELECT "name" FROM users WHERE email = "bob@example.com"`},
+		{"", `SELECT "name" FROM users WHERE email = "bob@example.com"`},
+	}
+
+	for _, tt := range tests {
+		if got := parseSQL(query, tt.system).Sanitized; got != tt.sanitized {
+			t.Errorf("%q: expected %q, got %q", tt.system, tt.sanitized, got)
+		}
+	}
+}
+
+func TestParseSQLBackslashEscapes(t *testing.T) {
+	tests := []struct {
+		name      string
+		system    string
+		query     string
+		sanitized string
+	}{
+		{"MySQLEscapedSingleQuote", "mysql", `SELECT * FROM users WHERE name = 'O\'Brien secret@mail.com' AND id = 1`, "SELECT * FROM users WHERE name = ? AND id = ?"},
+		{"MySQLEscapedBackslashSingle", "mysql", `SELECT * FROM files WHERE path = 'C:\\' AND owner = 'bob'`, "SELECT * FROM files WHERE path = ? AND owner = ?"},
+		{"MySQLEscapedDoubleQuote", "mysql", `SELECT * FROM users WHERE name = "say \"hi\" secret" AND id = 1`, "SELECT * FROM users WHERE name = ? AND id = ?"},
+		{"MariaDBEscapedBackslashDouble", "mariadb", `SELECT * FROM files WHERE path = "C:\\" AND owner = "bob"`, "SELECT * FROM files WHERE path = ? AND owner = ?"},
+		{"MySQLUnbalanced", "mysql", `SELECT * FROM users WHERE name = 'O\'Brien secret@mail.com`, "SELECT * FROM users WHERE name = ?"},
+		{"UnknownEscapedSingleQuote", "", `SELECT * FROM users WHERE name = 'O\'Brien secret@mail.com' AND id = 1`, "SELECT * FROM users WHERE name = ?"},
+		{"UnknownTrailingBackslash", "", `SELECT * FROM files WHERE path = 'C:\' AND owner = 'bob'`, "SELECT * FROM files WHERE path = ?"},
+		{"UnknownEscapedBackslash", "postgresql", `SELECT * FROM files WHERE path = 'C:\\' AND owner = 'bob'`, "SELECT * FROM files WHERE path = ?"},
+		{"EscapeString", "postgresql", `SELECT * FROM users WHERE name = E'O\'Brien' AND id = 1`, "SELECT * FROM users WHERE name = ? AND id = ?"},
+		{"QuotedIdentifier", "postgresql", `SELECT "C:\" FROM files WHERE owner = 'bob'`, `SELECT "C:\" FROM files WHERE owner = ?`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSQL(tt.query, tt.system).Sanitized; got != tt.sanitized {
+				t.Errorf("Sanitized:\n expected %q\n got      %q", tt.sanitized, got)
+			}
+		})
+	}
+}
+
+func TestDatabaseOperationDerivesAttributes(t *testing.T) {
+	kit, recorder := newRecordingKit(t, Config{ServiceName: "sql-test"})
+
+	err := kit.DatabaseOperation(context.Background(), "SELECT * FROM accounts WHERE ssn = '123-45-6789'", "", func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DatabaseOperation failed: %v", err)
+	}
+
+	span := findSpan(t, recorder.Ended(), "db.SELECT")
+	attrs := attributeMap(span.Attributes())
+	if attrs["db.table"] != "accounts" || attrs["db.operation"] != "SELECT" {
+		t.Errorf("Unexpected attributes: %v", attrs)
+	}
+	if got := attrs["db.query.text"]; got != "SELECT * FROM accounts WHERE ssn = ?" {
+		t.Errorf("Unexpected db.query.text: %q", got)
+	}
+
+	recorder.Reset()
+	err = kit.DatabaseOperationWithOptions(context.Background(), `DELETE FROM sessions WHERE token = "s3cr3t"`, "", func(ctx context.Context) error {
+		return nil
+	}, WithAttributes(semconv.DBSystemNameMySQL))
+	if err != nil {
+		t.Fatalf("DatabaseOperation failed: %v", err)
+	}
+	attrs = attributeMap(findSpan(t, recorder.Ended(), "db.DELETE").Attributes())
+	if got := attrs["db.query.text"]; got != "DELETE FROM sessions WHERE token = ?" {
+		t.Errorf("Expected MySQL double-quoted strings to be masked, got %q", got)
+	}
+}
+
+func FuzzParseSQL(f *testing.F) {
+	for _, seed := range []string{
+		"SELECT * FROM users WHERE id = 1",
+		"INSERT INTO t (a) VALUES ('x'), ('y''z')",
+		"UPDATE [a].[b] SET c = $1 WHERE d IN (1,2,3)",
+		"WITH x AS (SELECT 1) DELETE FROM y USING x",
+		"SELECT $a$ text $a$, E'\\'', 0x1F, 1.5e-3 /* c */ -- d",
+		"SELECT \"unterminated",
+		"SELECT ARRAY['a'] FROM `t`",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, query string) {
+		stmt := ParseSQL(query)
+
+		// Sanitizing twice must not change the result
+		if again := SanitizeSQL(stmt.Sanitized); again != stmt.Sanitized {
+			t.Errorf("Sanitization not idempotent:\n input %q\n once  %q\n twice %q", query, stmt.Sanitized, again)
+		}
+
+		// No literal survives sanitization
+		for _, tok := range lexSQL(stmt.Sanitized, false) {
+			if tok.kind == tokenLiteral {
+				t.Errorf("Literal %q left in sanitized output %q", tok.text, stmt.Sanitized)
+			}
+		}
+
+		if stmt.Operation != strings.ToUpper(stmt.Operation) {
+			t.Errorf("Operation not upper case: %q", stmt.Operation)
+		}
+		for _, table := range stmt.Tables {
+			if table == "" {
+				t.Errorf("Empty table name extracted from %q", query)
+			}
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package otelkit
+
+import (
+	"fmt"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// defaultMetricExportInterval and defaultMetricExportTimeout apply when
+// Config.MetricExportInterval and MetricExportTimeout are 0.
+const (
+	defaultMetricExportInterval = 15 * time.Second
+	defaultMetricExportTimeout  = 30 * time.Second
+)
+
+// MetricTemporality decides whether pushed metrics report totals since start
+// (cumulative) or only the change since the previous export (delta).
+type MetricTemporality string
+
+const (
+	// MetricTemporalityCumulative reports every instrument cumulatively (default)
+	MetricTemporalityCumulative MetricTemporality = "cumulative"
+
+	// MetricTemporalityDelta reports counters, observable counters and histograms
+	// as deltas, for backends with delta ingestion; up-down counters stay cumulative
+	MetricTemporalityDelta MetricTemporality = "delta"
+
+	// MetricTemporalityLowMemory reports synchronous counters and histograms as
+	// deltas, so the SDK need not keep their totals; the rest stays cumulative
+	MetricTemporalityLowMemory MetricTemporality = "lowmemory"
+)
+
+// Instrument kind names accepted as keys of Config.MetricTemporalityByKind.
+const (
+	InstrumentKindCounter                 = "counter"
+	InstrumentKindUpDownCounter           = "up_down_counter"
+	InstrumentKindHistogram               = "histogram"
+	InstrumentKindGauge                   = "gauge"
+	InstrumentKindObservableCounter       = "observable_counter"
+	InstrumentKindObservableUpDownCounter = "observable_up_down_counter"
+	InstrumentKindObservableGauge         = "observable_gauge"
+)
+
+// instrumentKinds maps the instrument kind names to the SDK kinds.
+var instrumentKinds = map[string]sdkmetric.InstrumentKind{
+	InstrumentKindCounter:                 sdkmetric.InstrumentKindCounter,
+	InstrumentKindUpDownCounter:           sdkmetric.InstrumentKindUpDownCounter,
+	InstrumentKindHistogram:               sdkmetric.InstrumentKindHistogram,
+	InstrumentKindGauge:                   sdkmetric.InstrumentKindGauge,
+	InstrumentKindObservableCounter:       sdkmetric.InstrumentKindObservableCounter,
+	InstrumentKindObservableUpDownCounter: sdkmetric.InstrumentKindObservableUpDownCounter,
+	InstrumentKindObservableGauge:         sdkmetric.InstrumentKindObservableGauge,
+}
+
+// temporalitySelector returns the temporality selector of the push exporters:
+// Config.MetricTemporality, with the kinds in Config.MetricTemporalityByKind
+// overridden. Only cumulative and delta are valid per kind.
+func temporalitySelector(config Config) (sdkmetric.TemporalitySelector, error) {
+	var deltaKinds map[sdkmetric.InstrumentKind]bool
+	switch config.MetricTemporality {
+	case "", MetricTemporalityCumulative:
+		deltaKinds = map[sdkmetric.InstrumentKind]bool{}
+	case MetricTemporalityDelta:
+		deltaKinds = map[sdkmetric.InstrumentKind]bool{
+			sdkmetric.InstrumentKindCounter:           true,
+			sdkmetric.InstrumentKindObservableCounter: true,
+			sdkmetric.InstrumentKindHistogram:         true,
+		}
+	case MetricTemporalityLowMemory:
+		deltaKinds = map[sdkmetric.InstrumentKind]bool{
+			sdkmetric.InstrumentKindCounter:   true,
+			sdkmetric.InstrumentKindHistogram: true,
+		}
+	default:
+		return nil, fmt.Errorf("unsupported metric temporality: %s", config.MetricTemporality)
+	}
+
+	for name, temporality := range config.MetricTemporalityByKind {
+		kind, ok := instrumentKinds[name]
+		if !ok {
+			return nil, fmt.Errorf("unsupported instrument kind in metric temporality: %s", name)
+		}
+		switch temporality {
+		case MetricTemporalityCumulative:
+			deltaKinds[kind] = false
+		case MetricTemporalityDelta:
+			deltaKinds[kind] = true
+		default:
+			return nil, fmt.Errorf("unsupported metric temporality for %s: %s", name, temporality)
+		}
+	}
+
+	return func(kind sdkmetric.InstrumentKind) metricdata.Temporality {
+		if deltaKinds[kind] {
+			return metricdata.DeltaTemporality
+		}
+		return metricdata.CumulativeTemporality
+	}, nil
+}
+
+// periodicReaderOptions returns the export interval and timeout of the push
+// exporters' periodic readers, with producers adding to what they collect;
+// zero values keep the defaults of 15s and 30s.
+func periodicReaderOptions(config Config, producers []sdkmetric.Producer) []sdkmetric.PeriodicReaderOption {
+	interval := config.MetricExportInterval
+	if interval <= 0 {
+		interval = defaultMetricExportInterval
+	}
+	timeout := config.MetricExportTimeout
+	if timeout <= 0 {
+		timeout = defaultMetricExportTimeout
+	}
+	opts := []sdkmetric.PeriodicReaderOption{
+		sdkmetric.WithInterval(interval),
+		sdkmetric.WithTimeout(timeout),
+	}
+	for _, producer := range producers {
+		opts = append(opts, sdkmetric.WithProducer(producer))
+	}
+	return opts
+}
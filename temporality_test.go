@@ -0,0 +1,91 @@
+package otelkit
+
+import (
+	"testing"
+	"time"
+
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestTemporalitySelector(t *testing.T) {
+	delta, cumulative := metricdata.DeltaTemporality, metricdata.CumulativeTemporality
+	kinds := []sdkmetric.InstrumentKind{
+		sdkmetric.InstrumentKindCounter,
+		sdkmetric.InstrumentKindUpDownCounter,
+		sdkmetric.InstrumentKindHistogram,
+		sdkmetric.InstrumentKindObservableCounter,
+		sdkmetric.InstrumentKindObservableUpDownCounter,
+		sdkmetric.InstrumentKindObservableGauge,
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []metricdata.Temporality
+	}{
+		{"Default", Config{}, []metricdata.Temporality{cumulative, cumulative, cumulative, cumulative, cumulative, cumulative}},
+		{"Delta", Config{MetricTemporality: MetricTemporalityDelta}, []metricdata.Temporality{delta, cumulative, delta, delta, cumulative, cumulative}},
+		{"LowMemory", Config{MetricTemporality: MetricTemporalityLowMemory}, []metricdata.Temporality{delta, cumulative, delta, cumulative, cumulative, cumulative}},
+		{"ByKind", Config{
+			MetricTemporality: MetricTemporalityDelta,
+			MetricTemporalityByKind: map[string]MetricTemporality{
+				InstrumentKindHistogram:     MetricTemporalityCumulative,
+				InstrumentKindUpDownCounter: MetricTemporalityDelta,
+			},
+		}, []metricdata.Temporality{delta, delta, cumulative, delta, cumulative, cumulative}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := temporalitySelector(tt.config)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			for i, kind := range kinds {
+				if got := selector(kind); got != tt.want[i] {
+					t.Errorf("%v: expected %v, got %v", kind, tt.want[i], got)
+				}
+			}
+		})
+	}
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, config := range []Config{
+			{MetricTemporality: "sometimes"},
+			{MetricTemporalityByKind: map[string]MetricTemporality{"timer": MetricTemporalityDelta}},
+			{MetricTemporalityByKind: map[string]MetricTemporality{InstrumentKindCounter: MetricTemporalityLowMemory}},
+		} {
+			if _, err := temporalitySelector(config); err == nil {
+				t.Errorf("Expected an error for %+v", config)
+			}
+		}
+
+		_, err := New(Config{ServiceName: "temporality", ExporterType: ExporterNone, EnableMetrics: true,
+			MetricsExporterType: ExporterStdout, MetricTemporality: "sometimes"})
+		if err == nil {
+			t.Error("Expected New to reject an unsupported temporality")
+		}
+	})
+}
+
+func TestMetricExportConfig(t *testing.T) {
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "5000")
+	t.Setenv("OTEL_METRIC_EXPORT_TIMEOUT", "2s")
+	t.Setenv("OTEL_EXPORTER_OTLP_METRICS_TEMPORALITY_PREFERENCE", "Delta")
+
+	config := DefaultConfig()
+	if config.MetricExportInterval != 5*time.Second {
+		t.Errorf("Expected an interval of 5s from milliseconds, got %v", config.MetricExportInterval)
+	}
+	if config.MetricExportTimeout != 2*time.Second {
+		t.Errorf("Expected a timeout of 2s from a Go duration, got %v", config.MetricExportTimeout)
+	}
+	if config.MetricTemporality != MetricTemporalityDelta {
+		t.Errorf("Expected delta temporality, got %q", config.MetricTemporality)
+	}
+
+	t.Setenv("OTEL_METRIC_EXPORT_INTERVAL", "soon")
+	if interval := DefaultConfig().MetricExportInterval; interval != defaultMetricExportInterval {
+		t.Errorf("Expected the default interval for an invalid value, got %v", interval)
+	}
+}
@@ -0,0 +1,250 @@
+package otelkit
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceOption configures the span created by the tracing helpers
+// (Trace, TraceDatabase, DatabaseOperation, HTTPMiddlewareWithOptions, etc.).
+// Options passed by the caller are applied after the helper's defaults, so
+// WithSpanKind overrides the default kind.
+type TraceOption func(*traceConfig)
+
+// traceConfig collects the settings applied by TraceOption values.
+//
+// Fields:
+//   - kind: The span kind (zero value leaves the helper's default in place)
+//   - links: Links to other spans
+//   - attrs: Attributes set when the span starts
+//   - startTime: Explicit start timestamp (zero value means "now")
+//   - resultAttributes: A func(T) []attribute.KeyValue stored untyped so one
+//     option type can serve every T
+//   - linkParent: Start a new trace linked to the parent span instead of a child span
+//   - recoverPanics: Return panics as *PanicError regardless of Config.RecoverPanics
+type traceConfig struct {
+	kind             trace.SpanKind
+	links            []trace.Link
+	attrs            []attribute.KeyValue
+	startTime        time.Time
+	resultAttributes any
+	linkParent       bool
+	recoverPanics    bool
+}
+
+// newTraceConfig applies the options in order; later options win.
+func newTraceConfig(opts []TraceOption) *traceConfig {
+	cfg := &traceConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(cfg)
+		}
+	}
+	return cfg
+}
+
+// spanStartOptions converts the collected settings into OpenTelemetry start options.
+func (c *traceConfig) spanStartOptions() []trace.SpanStartOption {
+	var opts []trace.SpanStartOption
+	if c.kind != trace.SpanKindUnspecified {
+		opts = append(opts, trace.WithSpanKind(c.kind))
+	}
+	if len(c.links) > 0 {
+		opts = append(opts, trace.WithLinks(c.links...))
+	}
+	if len(c.attrs) > 0 {
+		opts = append(opts, trace.WithAttributes(c.attrs...))
+	}
+	if !c.startTime.IsZero() {
+		opts = append(opts, trace.WithTimestamp(c.startTime))
+	}
+	return opts
+}
+
+// WithSpanKind sets the kind of the span (server, client, producer, consumer, internal).
+//
+// Example:
+//   otelkit.Trace(kit, ctx, "charge", fn, otelkit.WithSpanKind(trace.SpanKindClient))
+func WithSpanKind(kind trace.SpanKind) TraceOption {
+	return func(c *traceConfig) {
+		c.kind = kind
+	}
+}
+
+// WithLinks links the span to other spans, e.g. the producers of a batch of messages.
+func WithLinks(links ...trace.Link) TraceOption {
+	return func(c *traceConfig) {
+		c.links = append(c.links, links...)
+	}
+}
+
+// WithAttributes adds attributes to the span when it starts.
+// Attributes set at start time are visible to samplers.
+func WithAttributes(attrs ...attribute.KeyValue) TraceOption {
+	return func(c *traceConfig) {
+		c.attrs = append(c.attrs, attrs...)
+	}
+}
+
+// WithStartTime overrides the span start timestamp, e.g. when the work began
+// before the span could be created.
+func WithStartTime(start time.Time) TraceOption {
+	return func(c *traceConfig) {
+		c.startTime = start
+	}
+}
+
+// WithResultAttributes records attributes derived from the value returned by fn.
+// The callback only runs when fn succeeds, and only when T matches the type
+// parameter of the helper it is passed to.
+//
+// Example:
+//   user, err := otelkit.Trace(kit, ctx, "load_user", loadUser,
+//       otelkit.WithResultAttributes(func(u *User) []attribute.KeyValue {
+//           return []attribute.KeyValue{attribute.Int("user.id", u.ID)}
+//       }),
+//   )
+func WithResultAttributes[T any](fn func(result T) []attribute.KeyValue) TraceOption {
+	return func(c *traceConfig) {
+		c.resultAttributes = fn
+	}
+}
+
+// WithLinkedParent starts the span as the root of a new trace linked to the
+// span in ctx, instead of as its child. Use it for background work that
+// outlives the request that started it, so the request trace keeps its duration.
+func WithLinkedParent() TraceOption {
+	return func(c *traceConfig) {
+		c.linkParent = true
+	}
+}
+
+// withPanicRecovery makes a helper return panics as *PanicError even when
+// Config.RecoverPanics is off, for goroutines where re-panicking would crash
+// the process.
+func withPanicRecovery() TraceOption {
+	return func(c *traceConfig) {
+		c.recoverPanics = true
+	}
+}
+
+// traceWithOptions traces fn under a new span built from the given options.
+// TraceFunction and the typed helpers all funnel through here.
+func (o *OTelKit) traceWithOptions(ctx context.Context, spanName string, fn func(ctx context.Context) error, opts ...TraceOption) error {
+	cfg := newTraceConfig(opts)
+	startOpts := cfg.spanStartOptions()
+	if cfg.linkParent {
+		startOpts = append(startOpts, trace.WithNewRoot())
+		if parent := trace.SpanContextFromContext(ctx); parent.IsValid() {
+			startOpts = append(startOpts, trace.WithLinks(trace.Link{SpanContext: parent}))
+		}
+	}
+	return o.traceFunction(ctx, spanName, fn, o.config.RecoverPanics || cfg.recoverPanics, startOpts...)
+}
+
+// Trace traces fn like TraceFunction, but passes through the value fn returns.
+//
+// Parameters:
+//   - kit: The OTelKit instance that creates the span
+//   - ctx: Context for the operation (may contain parent span)
+//   - spanName: Descriptive name for the span
+//   - fn: The function to execute (receives the span context)
+//   - opts: Optional span settings (WithSpanKind, WithLinks, WithAttributes,
+//     WithStartTime, WithResultAttributes)
+//
+// Returns:
+//   - T: The value returned by fn
+//   - error: Any error returned by fn
+//
+// Example:
+//   total, err := otelkit.Trace(kit, ctx, "calculate_total", func(ctx context.Context) (float64, error) {
+//       return calculateTotal(ctx, orderID)
+//   }, otelkit.WithAttributes(attribute.String("order.id", orderID)))
+func Trace[T any](kit *OTelKit, ctx context.Context, spanName string, fn func(ctx context.Context) (T, error), opts ...TraceOption) (T, error) {
+	var result T
+	err := kit.traceWithOptions(ctx, spanName, typedCall(fn, &result, opts), opts...)
+	return result, err
+}
+
+// TraceDatabase is the typed equivalent of DatabaseOperation.
+//
+// Parameters:
+//   - kit: The OTelKit instance that creates the span
+//   - ctx: Context for the operation
+//   - operation: The database operation type (SELECT, INSERT, UPDATE, DELETE, etc.), or the full SQL statement
+//   - table: The database table name being operated on
+//   - fn: The function that performs the operation and returns its result
+//   - opts: Optional span settings
+//
+// Returns:
+//   - T: The value returned by fn
+//   - error: Any error returned by fn
+//
+// Example:
+//   user, err := otelkit.TraceDatabase(kit, ctx, "SELECT", "users", func(ctx context.Context) (*User, error) {
+//       return repo.FindUser(ctx, id)
+//   })
+func TraceDatabase[T any](kit *OTelKit, ctx context.Context, operation, table string, fn func(ctx context.Context) (T, error), opts ...TraceOption) (T, error) {
+	var result T
+	err := kit.DatabaseOperationWithOptions(ctx, operation, table, typedCall(fn, &result, opts), opts...)
+	return result, err
+}
+
+// TraceCache is the typed equivalent of CacheOperation.
+//
+// Parameters:
+//   - kit: The OTelKit instance that creates the span
+//   - ctx: Context for the operation
+//   - operation: The cache operation type (get, set, delete, etc.)
+//   - key: The cache key being operated on
+//   - fn: The function that performs the operation and returns its result
+//   - opts: Optional span settings
+//
+// Returns:
+//   - T: The value returned by fn
+//   - error: Any error returned by fn
+func TraceCache[T any](kit *OTelKit, ctx context.Context, operation, key string, fn func(ctx context.Context) (T, error), opts ...TraceOption) (T, error) {
+	var result T
+	err := kit.CacheOperationWithOptions(ctx, operation, key, typedCall(fn, &result, opts), opts...)
+	return result, err
+}
+
+// TraceExternal is the typed equivalent of ExternalServiceCall.
+//
+// Parameters:
+//   - kit: The OTelKit instance that creates the span
+//   - ctx: Context for the operation
+//   - serviceName: Name of the external service (e.g., "payment-api")
+//   - operation: The operation being performed (e.g., "charge")
+//   - fn: The function that makes the call and returns its result
+//   - opts: Optional span settings
+//
+// Returns:
+//   - T: The value returned by fn
+//   - error: Any error returned by fn
+func TraceExternal[T any](kit *OTelKit, ctx context.Context, serviceName, operation string, fn func(ctx context.Context) (T, error), opts ...TraceOption) (T, error) {
+	var result T
+	err := kit.ExternalServiceCallWithOptions(ctx, serviceName, operation, typedCall(fn, &result, opts), opts...)
+	return result, err
+}
+
+// typedCall adapts a value-returning fn to the func(ctx) error shape used by the
+// untyped helpers, storing the value in result and recording result attributes.
+func typedCall[T any](fn func(ctx context.Context) (T, error), result *T, opts []TraceOption) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		value, err := fn(ctx)
+		*result = value
+		if err != nil {
+			return err
+		}
+
+		cfg := newTraceConfig(opts)
+		if recordFn, ok := cfg.resultAttributes.(func(T) []attribute.KeyValue); ok {
+			trace.SpanFromContext(ctx).SetAttributes(recordFn(value)...)
+		}
+		return nil
+	}
+}
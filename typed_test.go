@@ -0,0 +1,103 @@
+package otelkit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTypedHelpers(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("TraceReturnsValue", func(t *testing.T) {
+		kit, recorder := newRecordingKit(t, Config{ServiceName: "typed-test"})
+
+		start := time.Now().Add(-time.Second)
+		got, err := Trace(kit, ctx, "compute", func(ctx context.Context) (int, error) {
+			return 42, nil
+		},
+			WithSpanKind(trace.SpanKindClient),
+			WithStartTime(start),
+			WithAttributes(attribute.String("input", "x")),
+			WithResultAttributes(func(v int) []attribute.KeyValue {
+				return []attribute.KeyValue{attribute.Int("result", v)}
+			}),
+		)
+		if err != nil || got != 42 {
+			t.Fatalf("Expected (42, nil), got (%d, %v)", got, err)
+		}
+
+		spans := recorder.Ended()
+		if len(spans) != 1 {
+			t.Fatalf("Expected 1 span, got %d", len(spans))
+		}
+		span := spans[0]
+		if span.SpanKind() != trace.SpanKindClient {
+			t.Errorf("Expected client span, got %v", span.SpanKind())
+		}
+		if !span.StartTime().Equal(start) {
+			t.Errorf("Expected start time %v, got %v", start, span.StartTime())
+		}
+		attrs := attributeMap(span.Attributes())
+		if attrs["input"] != "x" || attrs["result"] != "42" {
+			t.Errorf("Unexpected attributes: %v", attrs)
+		}
+	})
+
+	t.Run("TraceDatabaseError", func(t *testing.T) {
+		kit, recorder := newRecordingKit(t, Config{ServiceName: "typed-test"})
+
+		wantErr := errors.New("no rows")
+		called := false
+		_, err := TraceDatabase(kit, ctx, "SELECT", "users", func(ctx context.Context) (string, error) {
+			return "", wantErr
+		}, WithResultAttributes(func(string) []attribute.KeyValue {
+			called = true
+			return nil
+		}))
+		if !errors.Is(err, wantErr) {
+			t.Errorf("Expected %v, got %v", wantErr, err)
+		}
+		if called {
+			t.Error("Result attributes should not be recorded on error")
+		}
+		if name := recorder.Ended()[0].Name(); name != "db.SELECT" {
+			t.Errorf("Expected span db.SELECT, got %s", name)
+		}
+	})
+
+	t.Run("TraceCacheAndExternal", func(t *testing.T) {
+		kit, recorder := newRecordingKit(t, Config{ServiceName: "typed-test"})
+
+		hit, err := TraceCache(kit, ctx, "get", "user:1", func(ctx context.Context) (bool, error) {
+			return true, nil
+		})
+		if err != nil || !hit {
+			t.Errorf("Expected (true, nil), got (%v, %v)", hit, err)
+		}
+
+		status, err := TraceExternal(kit, ctx, "payments", "charge", func(ctx context.Context) (string, error) {
+			return "ok", nil
+		})
+		if err != nil || status != "ok" {
+			t.Errorf("Expected (ok, nil), got (%s, %v)", status, err)
+		}
+
+		if got := len(recorder.Ended()); got != 2 {
+			t.Errorf("Expected 2 spans, got %d", got)
+		}
+	})
+}
+
+// attributeMap flattens span attributes into strings for easy comparison.
+func attributeMap(attrs []attribute.KeyValue) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		m[string(attr.Key)] = attr.Value.Emit()
+	}
+	return m
+}
@@ -0,0 +1,306 @@
+package otelkit
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"gopkg.in/yaml.v3"
+)
+
+// Aggregation names accepted by MetricView.Aggregation.
+const (
+	// AggregationDefault keeps the instrument's default aggregation
+	AggregationDefault = "default"
+
+	// AggregationDrop drops the instrument entirely
+	AggregationDrop = "drop"
+
+	// AggregationSum aggregates measurements into a sum
+	AggregationSum = "sum"
+
+	// AggregationLastValue keeps only the last measurement
+	AggregationLastValue = "last_value"
+
+	// AggregationExplicitHistogram uses a histogram with MetricView.Buckets
+	AggregationExplicitHistogram = "explicit_bucket_histogram"
+
+	// AggregationExponentialHistogram uses a base-2 exponential histogram, which
+	// adapts its buckets to the recorded range
+	AggregationExponentialHistogram = "exponential_histogram"
+)
+
+// defaultExponentialMaxSize and defaultExponentialMaxScale are the SDK defaults
+// for exponential histograms, used when MetricView leaves them at 0.
+const (
+	defaultExponentialMaxSize  = 160
+	defaultExponentialMaxScale = 20
+)
+
+// MetricView changes how the metrics of matching instruments are aggregated and exported.
+//
+// Fields:
+//   - Instrument: Instrument name to match; may contain * and ? wildcards
+//   - Meter: Only match instruments of this meter (instrumentation scope) name (optional)
+//   - Rename: New metric name (not allowed with wildcards)
+//   - Description: New metric description (optional)
+//   - Aggregation: One of "default", "drop", "sum", "last_value",
+//     "explicit_bucket_histogram" or "exponential_histogram" (empty keeps the default)
+//   - Buckets: Bucket boundaries for explicit_bucket_histogram, strictly increasing
+//   - MaxSize: Maximum buckets of an exponential_histogram (0 = 160)
+//   - MaxScale: Maximum scale of an exponential_histogram (0 = 20)
+//   - AttributeKeys: Only keep these attributes (allow-list)
+//   - ExcludeAttributeKeys: Drop these attributes (deny-list)
+//
+// In a views file the fields use snake_case names (see LoadMetricViews).
+type MetricView struct {
+	Instrument           string    `yaml:"instrument"`
+	Meter                string    `yaml:"meter"`
+	Rename               string    `yaml:"rename"`
+	Description          string    `yaml:"description"`
+	Aggregation          string    `yaml:"aggregation"`
+	Buckets              []float64 `yaml:"buckets"`
+	MaxSize              int32     `yaml:"max_size"`
+	MaxScale             int32     `yaml:"max_scale"`
+	AttributeKeys        []string  `yaml:"attribute_keys"`
+	ExcludeAttributeKeys []string  `yaml:"exclude_attribute_keys"`
+}
+
+// metricViewsFile is the document read by LoadMetricViews.
+type metricViewsFile struct {
+	Views []MetricView `yaml:"views"`
+}
+
+// LoadMetricViews reads metric views from a YAML (or JSON) file.
+//
+// Parameters:
+//   - path: Path of the views file
+//
+// Returns:
+//   - []MetricView: The views, in file order
+//   - error: The file could not be read or parsed, or a view is invalid
+//
+// Example file:
+//   views:
+//     - instrument: http_request_duration_seconds
+//       aggregation: explicit_bucket_histogram
+//       buckets: [0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1]
+//       exclude_attribute_keys: [status_code]
+//     - instrument: otelkit_business_operations_total
+//       aggregation: drop
+func LoadMetricViews(path string) ([]MetricView, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metric views: %w", err)
+	}
+
+	var file metricViewsFile
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse metric views %s: %w", path, err)
+	}
+
+	for i, view := range file.Views {
+		if _, err := view.sdkView(); err != nil {
+			return nil, fmt.Errorf("metric views %s: view %d: %w", path, i, err)
+		}
+	}
+	return file.Views, nil
+}
+
+// sdkView validates the view and converts it to an sdkmetric.View.
+func (v MetricView) sdkView() (sdkmetric.View, error) {
+	if v.Instrument == "" {
+		return nil, fmt.Errorf("instrument is required")
+	}
+	if v.Rename != "" && strings.ContainsAny(v.Instrument, "*?") {
+		return nil, fmt.Errorf("instrument %q: rename cannot be used with wildcards", v.Instrument)
+	}
+	if len(v.Buckets) > 0 && v.Aggregation != AggregationExplicitHistogram {
+		return nil, fmt.Errorf("instrument %q: buckets require aggregation %q", v.Instrument, AggregationExplicitHistogram)
+	}
+
+	aggregation, err := v.aggregation()
+	if err != nil {
+		return nil, fmt.Errorf("instrument %q: %w", v.Instrument, err)
+	}
+
+	criteria := sdkmetric.Instrument{
+		Name:  v.Instrument,
+		Scope: instrumentation.Scope{Name: v.Meter},
+	}
+	mask := sdkmetric.Stream{
+		Name:            v.Rename,
+		Description:     v.Description,
+		Aggregation:     aggregation,
+		AttributeFilter: attributeKeysFilter(v.AttributeKeys, v.ExcludeAttributeKeys),
+	}
+	return sdkmetric.NewView(criteria, mask), nil
+}
+
+// aggregation returns the SDK aggregation for v.Aggregation; nil keeps the instrument's own.
+func (v MetricView) aggregation() (sdkmetric.Aggregation, error) {
+	switch v.Aggregation {
+	case "":
+		return nil, nil
+	case AggregationDefault:
+		return sdkmetric.AggregationDefault{}, nil
+	case AggregationDrop:
+		return sdkmetric.AggregationDrop{}, nil
+	case AggregationSum:
+		return sdkmetric.AggregationSum{}, nil
+	case AggregationLastValue:
+		return sdkmetric.AggregationLastValue{}, nil
+	case AggregationExplicitHistogram:
+		if err := validateBuckets(v.Buckets); err != nil {
+			return nil, err
+		}
+		return sdkmetric.AggregationExplicitBucketHistogram{Boundaries: v.Buckets}, nil
+	case AggregationExponentialHistogram:
+		return exponentialHistogram(v.MaxSize, v.MaxScale)
+	default:
+		return nil, fmt.Errorf("unsupported aggregation %q", v.Aggregation)
+	}
+}
+
+// exponentialHistogram returns a base-2 exponential histogram aggregation,
+// using the SDK defaults for a zero maxSize or maxScale.
+func exponentialHistogram(maxSize, maxScale int32) (sdkmetric.Aggregation, error) {
+	if maxSize == 0 {
+		maxSize = defaultExponentialMaxSize
+	}
+	if maxScale == 0 {
+		maxScale = defaultExponentialMaxScale
+	}
+	if maxSize < 2 || maxScale < -10 || maxScale > 20 {
+		return nil, fmt.Errorf("invalid exponential histogram: max_size must be at least 2 and max_scale between -10 and 20")
+	}
+	return sdkmetric.AggregationBase2ExponentialHistogram{MaxSize: maxSize, MaxScale: maxScale}, nil
+}
+
+// histogramAggregationSelector returns the aggregation selector of the metrics
+// exporter: with Config.ExponentialHistograms, histograms default to base-2
+// exponential histograms sized by ExponentialHistogramMaxSize and
+// ExponentialHistogramMaxScale; other instruments keep the SDK defaults.
+// MetricViews with an explicit aggregation still take precedence.
+func histogramAggregationSelector(config Config) (sdkmetric.AggregationSelector, error) {
+	if !config.ExponentialHistograms {
+		return sdkmetric.DefaultAggregationSelector, nil
+	}
+
+	exponential, err := exponentialHistogram(config.ExponentialHistogramMaxSize, config.ExponentialHistogramMaxScale)
+	if err != nil {
+		return nil, err
+	}
+	return func(kind sdkmetric.InstrumentKind) sdkmetric.Aggregation {
+		if kind == sdkmetric.InstrumentKindHistogram {
+			return exponential
+		}
+		return sdkmetric.DefaultAggregationSelector(kind)
+	}, nil
+}
+
+// attributeKeysFilter keeps the attributes in allow (all when empty) that are not in deny.
+// It returns nil, keeping every attribute, when both lists are empty.
+func attributeKeysFilter(allow, deny []string) attribute.Filter {
+	if len(allow) == 0 && len(deny) == 0 {
+		return nil
+	}
+
+	allowed := make(map[attribute.Key]bool, len(allow))
+	for _, key := range allow {
+		allowed[attribute.Key(key)] = true
+	}
+	denied := make(map[attribute.Key]bool, len(deny))
+	for _, key := range deny {
+		denied[attribute.Key(key)] = true
+	}
+
+	return func(kv attribute.KeyValue) bool {
+		return (len(allowed) == 0 || allowed[kv.Key]) && !denied[kv.Key]
+	}
+}
+
+// metricViews returns Config.MetricViews followed by the views of
+// Config.MetricViewsFile, after checking that they are valid.
+func metricViews(config Config) ([]MetricView, error) {
+	views := config.MetricViews
+	if config.MetricViewsFile != "" {
+		fileViews, err := LoadMetricViews(config.MetricViewsFile)
+		if err != nil {
+			return nil, err
+		}
+		views = append(append([]MetricView(nil), views...), fileViews...)
+	}
+
+	for i, view := range views {
+		if _, err := view.sdkView(); err != nil {
+			return nil, fmt.Errorf("metric view %d: %w", i, err)
+		}
+	}
+	return views, nil
+}
+
+// sdkViews converts views validated by metricViews to SDK views.
+func sdkViews(views []MetricView) []sdkmetric.View {
+	converted := make([]sdkmetric.View, 0, len(views))
+	for _, view := range views {
+		if sdkView, err := view.sdkView(); err == nil {
+			converted = append(converted, sdkView)
+		}
+	}
+	return converted
+}
+
+// matches reports whether v applies to the instrument name of the meter
+// (instrumentation scope) meterName, with the SDK's * and ? wildcards.
+func (v MetricView) matches(meterName, name string) bool {
+	if v.Meter != "" && v.Meter != meterName {
+		return false
+	}
+	if !strings.ContainsAny(v.Instrument, "*?") {
+		return v.Instrument == name
+	}
+	pattern := regexp.QuoteMeta(v.Instrument)
+	pattern = strings.ReplaceAll(strings.ReplaceAll(pattern, `\?`, "."), `\*`, ".*")
+	return regexp.MustCompile("^" + pattern + "$").MatchString(name)
+}
+
+// viewAttributeFilter returns the attributes that the views matching an
+// instrument keep, so the cardinality limiter counts series as they are
+// exported. It returns nil, keeping every attribute, unless each matching view
+// filters; with several views, an attribute is kept if any of them keeps it.
+func viewAttributeFilter(views []MetricView, meterName, name string) attribute.Filter {
+	var filters []attribute.Filter
+	for _, view := range views {
+		if !view.matches(meterName, name) {
+			continue
+		}
+		filter := attributeKeysFilter(view.AttributeKeys, view.ExcludeAttributeKeys)
+		if filter == nil {
+			return nil
+		}
+		filters = append(filters, filter)
+	}
+
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	}
+	return func(kv attribute.KeyValue) bool {
+		for _, filter := range filters {
+			if filter(kv) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,206 @@
+package otelkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// newViewsKit returns a kit whose metrics are collected through the given
+// views by the returned reader.
+func newViewsKit(t *testing.T, views []MetricView) (*OTelKit, *sdkmetric.ManualReader) {
+	t.Helper()
+	return newMetricsKit(t, Config{MetricViews: views})
+}
+
+func TestMetricViews(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("BucketsAndAttributes", func(t *testing.T) {
+		kit, reader := newViewsKit(t, []MetricView{{
+			Instrument:           "http_request_duration_seconds",
+			Aggregation:          AggregationExplicitHistogram,
+			Buckets:              []float64{0.001, 0.005},
+			ExcludeAttributeKeys: []string{"status_code"},
+		}})
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 2*time.Millisecond)
+		kit.RecordHTTPMetrics(ctx, "GET", "500", 2*time.Millisecond)
+
+		dp := findMetric(t, reader, "http_request_duration_seconds").Data.(metricdata.Histogram[float64]).DataPoints
+		if len(dp) != 1 || len(dp[0].Bounds) != 2 || dp[0].BucketCounts[1] != 2 {
+			t.Fatalf("Expected one series with both requests in the 5ms bucket, got %+v", dp)
+		}
+		if _, ok := dp[0].Attributes.Value("status_code"); ok {
+			t.Error("Expected status_code to be filtered out")
+		}
+	})
+
+	t.Run("AllowList", func(t *testing.T) {
+		kit, reader := newViewsKit(t, []MetricView{{Instrument: "otelkit_business_operations_total", AttributeKeys: []string{"operation_type"}}})
+		kit.RecordMetric(ctx, "order", 1, attribute.String("user.id", "1"))
+		kit.RecordMetric(ctx, "order", 1, attribute.String("user.id", "2"))
+
+		dp := findMetric(t, reader, "otelkit_business_operations_total").Data.(metricdata.Sum[int64]).DataPoints
+		if len(dp) != 1 || dp[0].Value != 2 || dp[0].Attributes.Len() != 1 {
+			t.Errorf("Expected a single operation_type series, got %+v", dp)
+		}
+	})
+
+	t.Run("RenameAndDrop", func(t *testing.T) {
+		kit, reader := newViewsKit(t, []MetricView{
+			{Instrument: "otelkit_business_operations_total", Rename: "business_ops_total", Description: "Business operations"},
+			{Instrument: "batch_*", Aggregation: AggregationDrop},
+		})
+		kit.RecordMetric(ctx, "order", 1)
+		kit.recordBatchItems(ctx, "emails", 3, 1)
+
+		m := findMetric(t, reader, "business_ops_total")
+		if m.Description != "Business operations" {
+			t.Errorf("Expected the new description, got %q", m.Description)
+		}
+		for _, m := range collectMetrics(t, reader) {
+			if strings.HasPrefix(m.Name, "batch_") {
+				t.Errorf("Expected %s to be dropped", m.Name)
+			}
+		}
+	})
+
+	t.Run("ExponentialHistogram", func(t *testing.T) {
+		kit, reader := newViewsKit(t, []MetricView{{Instrument: "http_request_duration_seconds", Aggregation: AggregationExponentialHistogram, MaxSize: 40}})
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 3*time.Millisecond)
+
+		m := findMetric(t, reader, "http_request_duration_seconds")
+		if dp := m.Data.(metricdata.ExponentialHistogram[float64]).DataPoints; len(dp) != 1 || dp[0].Count != 1 {
+			t.Errorf("Expected one exponential histogram point, got %+v", dp)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, view := range []MetricView{
+			{},
+			{Instrument: "http_*", Rename: "requests"},
+			{Instrument: "http_requests_total", Aggregation: "median"},
+			{Instrument: "http_requests_total", Buckets: []float64{1, 2}},
+			{Instrument: "http_request_duration_seconds", Aggregation: AggregationExplicitHistogram, Buckets: []float64{2, 1}},
+			{Instrument: "http_request_duration_seconds", Aggregation: AggregationExponentialHistogram, MaxScale: 30},
+		} {
+			if _, err := metricViews(Config{MetricViews: []MetricView{view}}); err == nil {
+				t.Errorf("Expected an error for %+v", view)
+			}
+		}
+	})
+}
+
+func TestLoadMetricViews(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("YAML", func(t *testing.T) {
+		views, err := LoadMetricViews(write("views.yaml", `
+views:
+  - instrument: http_request_duration_seconds
+    aggregation: explicit_bucket_histogram
+    buckets: [0.0005, 0.001, 0.005]
+    exclude_attribute_keys: [status_code]
+  - instrument: otelkit_business_operations_total
+    aggregation: drop
+`))
+		if err != nil {
+			t.Fatalf("LoadMetricViews failed: %v", err)
+		}
+		if len(views) != 2 || len(views[0].Buckets) != 3 || views[0].ExcludeAttributeKeys[0] != "status_code" || views[1].Aggregation != AggregationDrop {
+			t.Errorf("Unexpected views: %+v", views)
+		}
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		views, err := LoadMetricViews(write("views.json", `{"views": [{"instrument": "cache_*", "attribute_keys": ["result"]}]}`))
+		if err != nil || len(views) != 1 || views[0].AttributeKeys[0] != "result" {
+			t.Errorf("Unexpected views %+v, error %v", views, err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := LoadMetricViews(filepath.Join(dir, "missing.yaml")); err == nil {
+			t.Error("Expected an error for a missing file")
+		}
+		if _, err := LoadMetricViews(write("typo.yaml", "views:\n  - instrument: x\n    bucket: [1]\n")); err == nil {
+			t.Error("Expected an error for an unknown field")
+		}
+		if _, err := LoadMetricViews(write("invalid.yaml", "views:\n  - instrument: x\n    aggregation: median\n")); err == nil {
+			t.Error("Expected an error for an invalid view")
+		}
+	})
+
+	t.Run("Config", func(t *testing.T) {
+		path := write("config.yaml", "views:\n  - instrument: x\n    aggregation: median\n")
+		_, err := New(Config{ServiceName: "views-test", ExporterType: ExporterNone, EnableMetrics: true, MetricsExporterType: ExporterNone, MetricViewsFile: path})
+		if err == nil || !strings.Contains(err.Error(), "unsupported aggregation") {
+			t.Errorf("Expected New to reject the views file, got %v", err)
+		}
+	})
+}
+
+func TestExponentialHistograms(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("AllHistograms", func(t *testing.T) {
+		kit, reader := newMetricsKit(t, Config{ExponentialHistograms: true, ExponentialHistogramMaxSize: 20})
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 3*time.Millisecond)
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 3*time.Second)
+		kit.recordDBOperation(ctx, "postgresql", "SELECT", "users", 2*time.Millisecond, nil)
+
+		dp := findMetric(t, reader, "http_request_duration_seconds").Data.(metricdata.ExponentialHistogram[float64]).DataPoints
+		if len(dp) != 1 || dp[0].Count != 2 || len(dp[0].PositiveBucket.Counts) > 20 {
+			t.Errorf("Expected an exponential histogram of at most 20 buckets, got %+v", dp)
+		}
+		if _, ok := findMetric(t, reader, "db.client.operation.duration").Data.(metricdata.ExponentialHistogram[float64]); !ok {
+			t.Error("Expected db.client.operation.duration to be exponential")
+		}
+		if _, ok := findMetric(t, reader, "http_requests_total").Data.(metricdata.Sum[int64]); !ok {
+			t.Error("Expected counters to keep their aggregation")
+		}
+	})
+
+	t.Run("ViewOverride", func(t *testing.T) {
+		kit, reader := newMetricsKit(t, Config{
+			ExponentialHistograms: true,
+			MetricViews: []MetricView{
+				{Instrument: "http_request_duration_seconds", Aggregation: AggregationExponentialHistogram, MaxScale: 2},
+				{Instrument: "batch_duration_seconds", Aggregation: AggregationExplicitHistogram, Buckets: []float64{1, 10}},
+			},
+		})
+		kit.RecordHTTPMetrics(ctx, "GET", "200", 3*time.Millisecond)
+		kit.batchDuration.Record(ctx, 5)
+
+		if dp := findMetric(t, reader, "http_request_duration_seconds").Data.(metricdata.ExponentialHistogram[float64]).DataPoints; dp[0].Scale > 2 {
+			t.Errorf("Expected scale at most 2, got %d", dp[0].Scale)
+		}
+		if _, ok := findMetric(t, reader, "batch_duration_seconds").Data.(metricdata.Histogram[float64]); !ok {
+			t.Error("Expected the view to keep batch_duration_seconds explicit")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		if _, err := histogramAggregationSelector(Config{ExponentialHistograms: true, ExponentialHistogramMaxScale: 21}); err == nil {
+			t.Error("Expected an error for max scale 21")
+		}
+		_, err := New(Config{ServiceName: "exponential-test", ExporterType: ExporterNone, EnableMetrics: true, MetricsExporterType: ExporterNone, ExponentialHistograms: true, ExponentialHistogramMaxSize: 1})
+		if err == nil {
+			t.Error("Expected New to reject max size 1")
+		}
+	})
+}